@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A short code such as "7-crossword-puzzle" names a mailbox by its leading
+// number, the nameplate, and authenticates the peers with the whole code
+// through a PAKE; see newPAKE. The words never reach the signaling server.
+
+const codeNameplates = 1000
+
+var codeWords = [256]string{
+	"acorn", "album", "alpine", "amber", "anchor", "angle", "ankle", "apple",
+	"apron", "arch", "arena", "armor", "arrow", "atlas", "attic", "autumn",
+	"avenue", "bacon", "badge", "bagel", "bakery", "balloon", "bamboo",
+	"banana", "banjo", "barrel", "basket", "beacon", "beaver", "bench", "berry",
+	"bicycle", "biscuit", "blanket", "blender", "blossom", "border", "bottle",
+	"boulder", "bracket", "breeze", "brick", "bridge", "bronze", "brook",
+	"bubble", "bucket", "buffalo", "bundle", "butter", "button", "cabin",
+	"cactus", "camel", "candle", "canoe", "canvas", "canyon", "carbon",
+	"carpet", "carrot", "castle", "cattle", "cedar", "cellar", "chalk",
+	"cherry", "chimney", "cider", "cinema", "circus", "citrus", "clover",
+	"cobalt", "coconut", "comet", "compass", "copper", "coral", "cotton",
+	"cougar", "crater", "crayon", "cricket", "crossword", "crystal", "cupboard",
+	"curtain", "cushion", "dagger", "daisy", "desert", "diamond", "dolphin",
+	"domino", "donkey", "dragon", "drawer", "dune", "eagle", "easel", "echo",
+	"eclipse", "elbow", "ember", "engine", "falcon", "feather", "fence", "fern",
+	"ferry", "fiddle", "flute", "forest", "fossil", "fountain", "fox", "galaxy",
+	"garden", "garlic", "geyser", "ginger", "glacier", "globe", "goblet",
+	"gopher", "granite", "grape", "gravel", "guitar", "hammer", "harbor",
+	"harvest", "hazel", "helmet", "heron", "hickory", "honey", "hornet",
+	"igloo", "island", "ivory", "jacket", "jaguar", "jasmine", "jigsaw",
+	"jungle", "kayak", "kettle", "kiwi", "koala", "ladder", "lagoon", "lantern",
+	"laurel", "lemon", "lentil", "lily", "lizard", "lobster", "locket", "lotus",
+	"magnet", "mango", "maple", "marble", "meadow", "melon", "meteor", "mitten",
+	"mosaic", "muffin", "mustard", "napkin", "nectar", "needle", "noodle",
+	"nutmeg", "oasis", "ocean", "olive", "onion", "orbit", "orchid", "otter",
+	"oyster", "paddle", "pagoda", "panda", "parrot", "pasta", "peanut",
+	"pebble", "pelican", "pepper", "piano", "pickle", "pillow", "pirate",
+	"planet", "plaza", "pocket", "pony", "poppy", "potato", "pretzel", "prism",
+	"pumpkin", "puzzle", "quartz", "quilt", "rabbit", "radish", "raven",
+	"ribbon", "river", "rocket", "saddle", "salmon", "scarf", "shadow",
+	"shovel", "silver", "sketch", "sparrow", "spider", "spinach", "sponge",
+	"squash", "summit", "sunset", "teapot", "thistle", "thunder", "tiger",
+	"timber", "toast", "tomato", "trumpet", "tulip", "tunnel", "turnip",
+	"turtle", "umbrella", "valley", "velvet", "violin", "voyage", "waffle",
+	"walnut", "walrus", "whistle", "willow", "window", "zebra", "zigzag",
+}
+
+// newCode returns a random code of a nameplate and two words.
+func newCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(codeNameplates-1))
+	if err != nil {
+		return "", err
+	}
+	parts := []string{strconv.FormatInt(n.Int64()+1, 10)}
+	for i := 0; i < 2; i++ {
+		w, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeWords))))
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, codeWords[w.Int64()])
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// parseCode checks code and returns the connection key of its mailbox.
+func parseCode(code string) (string, error) {
+	parts := strings.Split(code, "-")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("code %q: want nameplate-word-word", code)
+	}
+	n, err := strconv.Atoi(parts[0])
+	if err != nil || n <= 0 {
+		return "", fmt.Errorf("code %q: invalid nameplate", code)
+	}
+	for _, w := range parts[1:] {
+		if w == "" {
+			return "", fmt.Errorf("code %q: empty word", code)
+		}
+	}
+	return "code-" + strconv.Itoa(n), nil
+}
+
+var (
+	errCodeBusy   = errors.New("code attempt in progress")
+	errCodeBurned = errors.New("code burned by a failed attempt")
+)
+
+// codeTimeout bounds the handshake of an attempt at a code, which keeps
+// other attempts out meanwhile.
+const codeTimeout = 10 * time.Second
+
+// codeGuard allows one attempt at a time per code and burns the code after
+// a failed one, so each wrong guess costs an attacker the code.
+type codeGuard struct {
+	mu     sync.Mutex
+	busy   map[string]bool
+	burned map[string]bool
+}
+
+var codes = &codeGuard{busy: map[string]bool{}, burned: map[string]bool{}}
+
+// acquire starts an attempt on the code of key.
+func (g *codeGuard) acquire(key string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.burned[key] {
+		return errCodeBurned
+	}
+	if g.busy[key] {
+		return errCodeBusy
+	}
+	g.busy[key] = true
+	return nil
+}
+
+// release ends the attempt on the code of key, burning it unless ok.
+func (g *codeGuard) release(key string, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.busy, key)
+	if !ok {
+		g.burned[key] = true
+	}
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// controlChannel is the label of the DataChannel, and the channel of the
+// offer, reserved for ping and bench: the server peer answers them itself
+// instead of dialing its target.
+const controlChannel = "control"
+
+// controlGreeting is the first line the server peer writes on the control
+// channel. A peer that does not know the channel dials its target instead,
+// whose first bytes tell it apart.
+const controlGreeting = "ssh-p2p control 1\n"
+
+const (
+	// maxControlFrame bounds the messages of ping and bench.
+	maxControlFrame = 1 << 20
+	// maxControlDuration bounds a download asked for by the client peer.
+	maxControlDuration = time.Minute
+	// controlStall is how long the client peer waits for an answer past
+	// the time an exchange should take before giving up on the channel.
+	controlStall = 10 * time.Second
+)
+
+// The control protocol is a sequence of commands, each a line from the
+// client peer followed by frames: a big-endian uint32 length and as many
+// bytes. An empty frame ends a sequence of frames.
+//
+//	ping              a frame, echoed back
+//	upload            frames until the empty one, answered by "ok <bytes>"
+//	download <size> <duration>
+//	                  frames of size bytes for duration, then the empty one
+
+// serveControl answers the commands of the client peer on conn until it
+// closes its side.
+func serveControl(conn net.Conn, logger *slog.Logger) error {
+	defer conn.Close()
+	if _, err := io.WriteString(conn, controlGreeting); err != nil {
+		return err
+	}
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			return errors.New("empty command")
+		}
+		switch {
+		case args[0] == "ping" && len(args) == 1:
+			b, err := readControlFrame(r, nil)
+			if err != nil {
+				return err
+			}
+			if err := writeControlFrame(w, b); err != nil {
+				return err
+			}
+		case args[0] == "upload" && len(args) == 1:
+			var n int64
+			var buf []byte
+			for {
+				if buf, err = readControlFrame(r, buf); err != nil {
+					return err
+				}
+				if len(buf) == 0 {
+					break
+				}
+				n += int64(len(buf))
+			}
+			logger.Info("upload received", "bytes", n)
+			fmt.Fprintf(w, "ok %d\n", n)
+		case args[0] == "download" && len(args) == 3:
+			size, err1 := strconv.Atoi(args[1])
+			d, err2 := time.ParseDuration(args[2])
+			if err1 != nil || err2 != nil || size <= 0 || size > maxControlFrame || d <= 0 || d > maxControlDuration {
+				return fmt.Errorf("invalid command %q", strings.TrimSpace(line))
+			}
+			n, err := sendFrames(w, size, d)
+			if err != nil {
+				return err
+			}
+			logger.Info("download sent", "bytes", n, "size", size)
+		default:
+			return fmt.Errorf("unknown command %q", strings.TrimSpace(line))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// sendFrames writes frames of size bytes to w for d, then the empty frame,
+// and returns the bytes sent.
+func sendFrames(w *bufio.Writer, size int, d time.Duration) (int64, error) {
+	b := make([]byte, size)
+	var n int64
+	for end := time.Now().Add(d); time.Now().Before(end); n += int64(size) {
+		if err := writeControlFrame(w, b); err != nil {
+			return n, err
+		}
+	}
+	return n, writeControlFrame(w, nil)
+}
+
+func writeControlFrame(w *bufio.Writer, b []byte) error {
+	var head [4]byte
+	binary.BigEndian.PutUint32(head[:], uint32(len(b)))
+	if _, err := w.Write(head[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// readControlFrame reads a frame into buf, which it grows as needed.
+func readControlFrame(r *bufio.Reader, buf []byte) ([]byte, error) {
+	var head [4]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(head[:])
+	if n > maxControlFrame {
+		return nil, fmt.Errorf("frame of %d bytes too large", n)
+	}
+	if cap(buf) < int(n) {
+		buf = make([]byte, n)
+	}
+	buf = buf[:n]
+	_, err := io.ReadFull(r, buf)
+	return buf, err
+}
+
+// controlClient speaks the control protocol to the server peer.
+type controlClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// newControlClient waits up to timeout for the greeting of the server peer
+// on conn.
+func newControlClient(conn net.Conn, timeout time.Duration) (*controlClient, error) {
+	c := &controlClient{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	defer conn.SetReadDeadline(time.Time{})
+	line, err := c.r.ReadString('\n')
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe):
+		return nil, errors.New("session closed")
+	case isTimeout(err):
+		return nil, errors.New("no session within " + timeout.String())
+	case err != nil:
+		return nil, err
+	}
+	if line != controlGreeting {
+		return nil, errors.New("the server peer does not answer ping and bench")
+	}
+	return c, nil
+}
+
+// ping sends a frame of size bytes and returns the time its echo took.
+func (c *controlClient) ping(size int) (time.Duration, error) {
+	start := time.Now()
+	c.deadline(0)
+	defer c.conn.SetDeadline(time.Time{})
+	io.WriteString(c.w, "ping\n")
+	if err := writeControlFrame(c.w, make([]byte, size)); err != nil {
+		return 0, err
+	}
+	if err := c.w.Flush(); err != nil {
+		return 0, err
+	}
+	b, err := readControlFrame(c.r, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != size {
+		return 0, fmt.Errorf("echo of %d bytes for %d", len(b), size)
+	}
+	return time.Since(start), nil
+}
+
+// upload sends frames of size bytes for d and returns the bytes the server
+// peer received and the time until it acknowledged them, or the bytes sent
+// and an error.
+func (c *controlClient) upload(size int, d time.Duration) (int64, time.Duration, error) {
+	start := time.Now()
+	c.deadline(d)
+	defer c.conn.SetDeadline(time.Time{})
+	io.WriteString(c.w, "upload\n")
+	sent, err := sendFrames(c.w, size, d)
+	if err == nil {
+		err = c.w.Flush()
+	}
+	if err != nil {
+		return sent, 0, err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return 0, 0, err
+	}
+	var n int64
+	if _, err := fmt.Sscanf(line, "ok %d\n", &n); err != nil {
+		return 0, 0, fmt.Errorf("unexpected answer %q", line)
+	}
+	return n, time.Since(start), nil
+}
+
+// download asks the server peer for frames of size bytes for d and
+// returns the bytes received and the time they took, or an error.
+func (c *controlClient) download(size int, d time.Duration) (int64, time.Duration, error) {
+	start := time.Now()
+	c.deadline(d)
+	defer c.conn.SetDeadline(time.Time{})
+	fmt.Fprintf(c.w, "download %d %s\n", size, d)
+	if err := c.w.Flush(); err != nil {
+		return 0, 0, err
+	}
+	var n int64
+	var buf []byte
+	for {
+		var err error
+		if buf, err = readControlFrame(c.r, buf); err != nil {
+			return n, 0, err
+		}
+		if len(buf) == 0 {
+			return n, time.Since(start), nil
+		}
+		n += int64(len(buf))
+	}
+}
+
+// deadline gives an exchange meant to take d until controlStall past it.
+func (c *controlClient) deadline(d time.Duration) {
+	c.conn.SetDeadline(time.Now().Add(d + controlStall))
+}
+
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// dialControl opens the control channel of the peer serving the key of
+// rule, with the configuration in effect, and waits up to timeout for it.
+func dialControl(rule clientRule, timeout time.Duration) (*controlClient, error) {
+	rule.control = true
+	local, remote := net.Pipe()
+	go connect(context.Background(), rule, remote)
+	c, err := newControlClient(local, timeout)
+	if err != nil {
+		local.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// runPing sends count pings of size bytes, one per interval or until ctx
+// is done when count is 0, and writes their round-trip times to w.
+func runPing(ctx context.Context, c *controlClient, w io.Writer, count, size int, interval time.Duration) error {
+	var sent, answered int
+	var min, max, sum time.Duration
+	defer func() {
+		if sent == 0 {
+			return
+		}
+		fmt.Fprintf(w, "--- %d sent, %d answered", sent, answered)
+		if answered > 0 {
+			avg := sum / time.Duration(answered)
+			fmt.Fprintf(w, ", rtt min/avg/max %v/%v/%v", min.Round(time.Microsecond), avg.Round(time.Microsecond), max.Round(time.Microsecond))
+		}
+		fmt.Fprintln(w)
+	}()
+	for seq := 1; count == 0 || seq <= count; seq++ {
+		if seq > 1 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(interval):
+			}
+		}
+		sent++
+		rtt, err := c.ping(size)
+		if isTimeout(err) {
+			return fmt.Errorf("no answer to ping %d within %v, the channel stalled", seq, controlStall)
+		}
+		if err != nil {
+			return err
+		}
+		answered++
+		sum += rtt
+		if min == 0 || rtt < min {
+			min = rtt
+		}
+		if rtt > max {
+			max = rtt
+		}
+		fmt.Fprintf(w, "%d bytes: seq=%d time=%v\n", size, seq, rtt.Round(time.Microsecond))
+	}
+	return nil
+}
+
+// runBench measures the throughput of each direction for d with messages
+// of each of sizes, and writes a line per size to w as it goes.
+func runBench(c *controlClient, w io.Writer, sizes []int, d time.Duration) error {
+	fmt.Fprintf(w, "%-8s  %-12s  %s\n", "size", "upload", "download")
+	for _, size := range sizes {
+		up, upTime, err := c.upload(size, d)
+		if err != nil {
+			return stalled("upload", size, up, err)
+		}
+		down, downTime, err := c.download(size, d)
+		if err != nil {
+			return stalled("download", size, down, err)
+		}
+		fmt.Fprintf(w, "%-8d  %-12s  %s\n", size, throughput(up, upTime), throughput(down, downTime))
+	}
+	return nil
+}
+
+// stalled describes a failed transfer of n bytes in messages of size.
+func stalled(direction string, size int, n int64, err error) error {
+	if isTimeout(err) {
+		return fmt.Errorf("%s of %d-byte messages stalled after %d bytes", direction, size, n)
+	}
+	return err
+}
+
+func throughput(n int64, d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f MB/s", float64(n)/d.Seconds()/1e6)
+}
+
+// parseSizes parses a comma-separated list of message sizes.
+func parseSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, v := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n <= 0 || n > maxControlFrame {
+			return nil, fmt.Errorf("invalid message size %q, expected 1 to %d", v, maxControlFrame)
+		}
+		sizes = append(sizes, n)
+	}
+	return sizes, nil
+}
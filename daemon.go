@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/nobonobo/ssh-p2p/signaling"
+)
+
+// daemon runs the forwarding rules of a configuration and replaces them on
+// reload. Sessions that were already established are never interrupted: only
+// the pull loops and listeners that feed new sessions are restarted.
+type daemon struct {
+	ctx context.Context
+
+	mu      sync.Mutex
+	rules   atomic.Value // map[string]serverRule by key
+	keys    []string     // keys of the running pull loop
+	uri     string       // signaling server of the running pull loop
+	tr      signaling.Transport
+	stop    context.CancelFunc
+	clients map[string]*runningClient // by listen address
+	logFile *os.File
+}
+
+type runningClient struct {
+	rule atomic.Value // clientRule
+	l    net.Listener
+}
+
+func newDaemon(ctx context.Context) *daemon {
+	d := &daemon{
+		ctx:     ctx,
+		clients: map[string]*runningClient{},
+	}
+	d.rules.Store(map[string]serverRule{})
+	return d
+}
+
+// serverRules returns the server rules in effect by key.
+func (d *daemon) serverRules() map[string]serverRule {
+	return d.rules.Load().(map[string]serverRule)
+}
+
+// apply makes c the configuration in effect. Whatever may fail, loading
+// the identity, opening the log and binding new listeners, is done first,
+// so that a failed reload leaves the running configuration untouched; the
+// log, config and rules are then switched together.
+func (d *daemon) apply(c *config) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// c is complete before it is published: sessions read it concurrently.
+	if len(c.Servers) > 0 && c.identity == nil {
+		key, err := loadIdentity(c.identityPath())
+		if err != nil {
+			return err
+		}
+		c.identity = key
+	}
+	lg, err := openLog(c.Log)
+	if err != nil {
+		return err
+	}
+	listens := map[string]clientRule{}
+	for _, r := range c.Clients {
+		listens[r.Listen] = r
+	}
+	bound := map[string]net.Listener{}
+	for addr := range listens {
+		if _, ok := d.clients[addr]; ok {
+			continue
+		}
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, l := range bound {
+				l.Close()
+			}
+			lg.discard()
+			return err
+		}
+		bound[addr] = l
+	}
+
+	d.setLog(lg)
+	current.Store(c)
+	if urls := c.unusedICE(); len(urls) > 0 {
+		slog.Warn("ice servers unsupported, no candidates are gathered from them", "urls", strings.Join(urls, ","))
+	}
+
+	// All keys share one pull loop, which is restarted only when the set of
+	// keys or the signaling server changes. Other rule changes apply from the
+	// next offer.
+	rules := map[string]serverRule{}
+	var keys []string
+	for _, r := range c.Servers {
+		rules[r.Key] = r
+		keys = append(keys, r.Key)
+	}
+	sort.Strings(keys)
+	d.rules.Store(rules)
+	if !reflect.DeepEqual(keys, d.keys) || c.signalingURI() != d.uri || !reflect.DeepEqual(c.transport(), d.tr) {
+		if d.stop != nil {
+			slog.Info("server stopped", "keys", strings.Join(d.keys, ","))
+			d.stop()
+			d.stop = nil
+		}
+		d.keys, d.uri, d.tr = keys, c.signalingURI(), c.transport()
+		if len(keys) > 0 {
+			ctx, cancel := context.WithCancel(d.ctx)
+			d.stop = cancel
+			go serve(ctx, c.signalingClient(), keys, d.serverRules)
+		}
+	}
+
+	for addr, cl := range d.clients {
+		if r, ok := listens[addr]; ok {
+			cl.rule.Store(r)
+			continue
+		}
+		slog.Info("listen stopped", "addr", addr)
+		cl.l.Close()
+		delete(d.clients, addr)
+	}
+	for addr, l := range bound {
+		slog.Info("listening", "addr", addr)
+		cl := &runningClient{l: l}
+		cl.rule.Store(listens[addr])
+		d.clients[addr] = cl
+		go d.accept(cl)
+	}
+	return nil
+}
+
+func (d *daemon) accept(cl *runningClient) {
+	for {
+		sock, err := cl.l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			slog.Warn("accept failed", "err", err)
+			continue
+		}
+		rule := cl.rule.Load().(clientRule)
+		if addr, ok := sock.RemoteAddr().(*net.TCPAddr); ok && !rule.allow.Contains(addr.IP) {
+			slog.Warn("connection rejected", "from", sock.RemoteAddr(), "key", rule.Key)
+			sock.Close()
+			continue
+		}
+		go connect(d.ctx, rule, sock)
+	}
+}
+
+// preparedLog is a logger opened by openLog and not yet in effect.
+type preparedLog struct {
+	h     slog.Handler
+	level slog.Level
+	file  *os.File // nil for stderr
+}
+
+// openLog opens the logger described by lc. The file is reopened on every
+// reload so that rotated logs are picked up.
+func openLog(lc logConfig) (*preparedLog, error) {
+	if lc.File == "" {
+		h, l, err := newLogHandler(os.Stderr, lc)
+		if err != nil {
+			return nil, err
+		}
+		return &preparedLog{h: h, level: l}, nil
+	}
+	f, err := os.OpenFile(lc.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	h, l, err := newLogHandler(f, lc)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &preparedLog{h: h, level: l, file: f}, nil
+}
+
+// discard closes the file of a logger that is not put in effect.
+func (lg *preparedLog) discard() {
+	if lg.file != nil {
+		lg.file.Close()
+	}
+}
+
+// setLog puts lg in effect and closes the file of the previous logger.
+func (d *daemon) setLog(lg *preparedLog) {
+	installLogger(lg.h, lg.level)
+	if d.logFile != nil {
+		d.logFile.Close()
+	}
+	d.logFile = lg.file
+}
+
+// closeWait bounds the wait of shutdown for the sessions it closes.
+const closeWait = 5 * time.Second
+
+// shutdown stops accepting new offers and connections, notifies the peers
+// of live sessions and waits up to timeout for them to finish before closing
+// whatever is left. A value on force cuts the wait short.
+func (d *daemon) shutdown(timeout time.Duration, force <-chan os.Signal) {
+	d.mu.Lock()
+	if d.stop != nil {
+		d.stop()
+		d.stop = nil
+	}
+	for addr, cl := range d.clients {
+		cl.l.Close()
+		delete(d.clients, addr)
+	}
+	d.mu.Unlock()
+
+	live := sessions.list()
+	slog.Info("draining", "sessions", len(live), "timeout", timeout)
+	for _, s := range live {
+		if err := s.notify(noticeShutdown); err != nil {
+			s.log.Warn("notify failed", "err", err)
+		}
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-sessions.drained():
+	case <-timer.C:
+	case <-force:
+		slog.Warn("drain interrupted")
+	}
+	left := sessions.list()
+	// Closing a session may block on a dead peer, so they are closed at
+	// once and waited for with a bound.
+	var closed atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for _, s := range left {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.Close()
+				closed.Add(1)
+			}()
+		}
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(closeWait):
+		slog.Warn("sessions still closing", "sessions", len(left)-int(closed.Load()))
+	}
+	slog.Info("shutdown", "served", sessions.served(), "drained", len(live)-len(left), "closed", closed.Load())
+}
+
+// run applies the configuration returned by load and reloads it on SIGHUP.
+// SIGINT or SIGTERM shuts down gracefully, draining live sessions for the
+// configured drain_timeout or drain when it is unset.
+func run(load func() (*config, error), drain time.Duration) {
+	c, err := load()
+	if err != nil {
+		fatal("invalid config", err)
+	}
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := newDaemon(ctx)
+	if err := d.apply(c); err != nil {
+		fatal("start failed", err)
+	}
+	for s := range sig {
+		if s != syscall.SIGHUP {
+			slog.Info("signal received", "signal", s)
+			break
+		}
+		c, err := load()
+		if err != nil {
+			slog.Error("reload failed", "err", err)
+			continue
+		}
+		if err := d.apply(c); err != nil {
+			slog.Error("reload failed", "err", err)
+			continue
+		}
+		slog.Info("reloaded")
+	}
+	// An explicit drain_timeout of 0 closes live sessions right away.
+	if c := currentConfig(); c.DrainTimeout != "" {
+		drain = c.drainTimeout
+	}
+	d.shutdown(drain, sig)
+}
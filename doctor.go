@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nobonobo/ssh-p2p/signaling"
+	"github.com/nobonobo/ssh-p2p/turn"
+	"github.com/pions/webrtc"
+	"github.com/pions/webrtc/pkg/ice"
+)
+
+// doctorTimeout bounds each network check of doctor.
+const doctorTimeout = 3 * time.Second
+
+// NAT behaviours told apart by doctor. Filtering, which tells a full cone
+// from a restricted one, needs servers answering from another address and
+// is not tested: "cone" covers them all.
+const (
+	natNone      = "none"      // the reflexive address is a local one
+	natCone      = "cone"      // endpoint-independent mapping
+	natSymmetric = "symmetric" // a mapping per destination
+	natUnknown   = "unknown"   // a single server answered
+	natBlocked   = "blocked"   // no server answered
+)
+
+// doctor checks what sessions of conf need: the signaling server, the
+// STUN servers and the NAT behaviour they reveal, the credentials of TURN
+// servers and the ICE candidates gathered. It writes a report to w and
+// reports whether a session is likely to connect.
+func doctor(w io.Writer, conf *config) bool {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+	row := func(check, target, result string) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", check, target, result)
+	}
+
+	base := conf.signalingURI()
+	signaled := true
+	if d, err := checkSignaling(conf.signalingClient()); err != nil {
+		signaled = false
+		row("signaling", base, "failed: "+err.Error())
+	} else {
+		row("signaling", base, "ok "+d.Round(time.Millisecond).String())
+	}
+
+	network := conf.iceNetwork()
+	if network == "udp" {
+		network = "udp4"
+	}
+	var urls []string
+	var servers []*net.UDPAddr
+	seen := map[string]bool{}
+	for _, s := range doctorServers(conf) {
+		for _, u := range s.URLs {
+			url, err := ice.ParseURL(u)
+			if err != nil || (url.Scheme != ice.SchemeTypeSTUN && url.Scheme != ice.SchemeTypeTURN) || url.Proto != ice.ProtoTypeUDP {
+				continue
+			}
+			addr, err := net.ResolveUDPAddr(network, net.JoinHostPort(url.Host, strconv.Itoa(url.Port)))
+			if err != nil {
+				row("stun", u, "failed: "+err.Error())
+				continue
+			}
+			if seen[addr.String()] {
+				continue
+			}
+			seen[addr.String()] = true
+			urls = append(urls, u)
+			servers = append(servers, addr)
+		}
+	}
+	nat := natUnknown
+	if len(servers) == 0 {
+		row("stun", "", "no STUN server")
+	} else {
+		conn, err := net.ListenPacket(network, ":0")
+		if err != nil {
+			row("stun", "", "failed: "+err.Error())
+		} else {
+			mapped := turn.Bind(conn, servers, doctorTimeout)
+			conn.Close()
+			for i, a := range mapped {
+				if a == nil {
+					row("stun", urls[i], "no answer")
+				} else {
+					row("stun", urls[i], "mapped "+a.String())
+				}
+			}
+			nat = classifyNAT(mapped, localIPs())
+			row("nat", "", natDescription(nat))
+		}
+	}
+
+	for _, s := range doctorServers(conf) {
+		for _, u := range s.URLs {
+			url, err := ice.ParseURL(u)
+			if err != nil || (url.Scheme != ice.SchemeTypeTURN && url.Scheme != ice.SchemeTypeTURNS) {
+				continue
+			}
+			if s.Username == "" {
+				row("turn", u, "no credentials to check")
+				continue
+			}
+			relay, transport, err := checkTURN(url, s.Username, s.Credential)
+			if err != nil {
+				row("turn", u, "failed over "+transport+": "+err.Error())
+			} else {
+				row("turn", u, "credentials ok over "+transport+", relay "+relay.String())
+			}
+		}
+	}
+
+	candidates := 0
+	listed := map[string]bool{}
+	if sdp, err := gatherCandidates(conf); err != nil {
+		row("candidate", "", "failed: "+err.Error())
+	} else {
+		for _, line := range strings.Split(sdp, "\n") {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, "a=candidate:") {
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) < 6 {
+				continue
+			}
+			typ, addr := candidateTypes(line), net.JoinHostPort(fields[4], fields[5])
+			if listed[typ+" "+addr] {
+				continue
+			}
+			listed[typ+" "+addr] = true
+			candidates++
+			row("candidate", typ, addr)
+		}
+		if candidates == 0 {
+			row("candidate", "", "none")
+		}
+	}
+
+	ok, verdict := doctorVerdict(conf, signaled, nat, candidates)
+	row("verdict", "", verdict)
+	return ok
+}
+
+// doctorServers returns the ICE servers of conf, or those pions uses by
+// default.
+func doctorServers(conf *config) []iceServer {
+	if s := conf.iceServers(); len(s) > 0 {
+		return s
+	}
+	var servers []iceServer
+	for _, s := range defaultRTCConfiguration.IceServers {
+		servers = append(servers, iceServer{URLs: s.URLs})
+	}
+	return servers
+}
+
+// checkSignaling posts an empty message to a mailbox nobody pulls, which
+// the signaling server accepts and drops.
+func checkSignaling(client *signaling.Client) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	start := time.Now()
+	if err := client.Push(ctx, "doctor-"+uuid.New().String(), signaling.ConnectInfo{}); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// classifyNAT tells the NAT behaviour from the addresses mapped by distinct
+// servers for one local port, nil for those that did not answer.
+func classifyNAT(mapped []*net.UDPAddr, local []net.IP) string {
+	var first *net.UDPAddr
+	answered := 0
+	for _, a := range mapped {
+		if a == nil {
+			continue
+		}
+		answered++
+		if first == nil {
+			first = a
+			continue
+		}
+		if !a.IP.Equal(first.IP) || a.Port != first.Port {
+			return natSymmetric
+		}
+	}
+	switch {
+	case answered == 0:
+		return natBlocked
+	case containsIP(local, first.IP):
+		return natNone
+	case answered == 1:
+		return natUnknown
+	}
+	return natCone
+}
+
+func natDescription(nat string) string {
+	switch nat {
+	case natNone:
+		return "none, the mapped address is a local one"
+	case natCone:
+		return "cone, endpoint-independent mapping"
+	case natSymmetric:
+		return "symmetric, a new mapping per destination"
+	case natUnknown:
+		return "unknown, two STUN servers are needed to tell the mapping"
+	}
+	return "unknown, UDP to the STUN servers seems blocked"
+}
+
+// doctorVerdict sums up the report.
+func doctorVerdict(conf *config, signaled bool, nat string, candidates int) (bool, string) {
+	relay := conf.relayAfter() > 0
+	switch {
+	case !signaled:
+		return false, "failed: offers cannot reach the peer without the signaling server"
+	case conf.icePolicy() == policyRelay && candidates > 0:
+		return true, "ok: sessions go through the TURN servers (ice policy relay)"
+	case candidates == 0 && relay:
+		return true, "ok: no candidates, sessions go through the signaling relay"
+	case candidates == 0:
+		return false, "failed: no candidates and the relay is turned off"
+	case conf.icePolicy() == policyHost:
+		return true, "likely on the same network: only host candidates are offered"
+	case nat == natNone || nat == natCone:
+		return true, "likely: direct connections should work"
+	case nat == natUnknown:
+		return true, "likely: direct connections should work unless the NAT is symmetric"
+	case nat == natBlocked && relay:
+		return true, "ok: UDP seems blocked, sessions fall back to the signaling relay"
+	case nat == natBlocked:
+		return false, "unlikely: UDP seems blocked and the relay is turned off"
+	case relay:
+		return true, "ok: direct connections may fail behind a symmetric NAT, sessions fall back to the signaling relay"
+	}
+	return false, "unlikely: direct connections may fail behind a symmetric NAT and the relay is turned off"
+}
+
+// checkTURN allocates a relay on the TURN server of url and deletes it,
+// and returns its address and the transport used.
+func checkTURN(url *ice.URL, user, pass string) (*net.UDPAddr, string, error) {
+	addr := net.JoinHostPort(url.Host, strconv.Itoa(url.Port))
+	var conn net.Conn
+	var err error
+	transport := url.Proto.String()
+	dialer := &net.Dialer{Timeout: doctorTimeout}
+	switch {
+	case url.Scheme == ice.SchemeTypeTURNS:
+		transport = "tls"
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: url.Host})
+	default:
+		conn, err = dialer.Dial(transport, addr)
+	}
+	if err != nil {
+		return nil, transport, err
+	}
+	defer conn.Close()
+	relay, err := turn.Allocate(conn, user, pass, doctorTimeout)
+	return relay, transport, err
+}
+
+// gatherCandidates returns an offer of conf with the candidates that
+// sessions would offer.
+func gatherCandidates(conf *config) (string, error) {
+	rtcConf, err := conf.rtcConfiguration()
+	if err != nil {
+		return "", err
+	}
+	pc, err := webrtc.New(rtcConf)
+	if err != nil {
+		return "", err
+	}
+	defer pc.Close()
+	if _, err := pc.CreateDataChannel("data", nil); err != nil {
+		return "", err
+	}
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		return "", err
+	}
+	return offer.Sdp, nil
+}
+
+// localIPs returns the addresses of the local interfaces.
+func localIPs() []net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok {
+			ips = append(ips, n.IP)
+		}
+	}
+	return ips
+}
+
+func containsIP(ips []net.IP, ip net.IP) bool {
+	for _, v := range ips {
+		if v.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
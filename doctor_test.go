@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nobonobo/ssh-p2p/signaling"
+	"github.com/nobonobo/ssh-p2p/turn"
+)
+
+// stunServer answers binding requests on loopback and returns its address.
+func stunServer(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := turn.NewServer(conn, turn.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+	return conn.LocalAddr().String()
+}
+
+func TestDoctor(t *testing.T) {
+	ts := httptest.NewServer(signaling.NewServer())
+	defer ts.Close()
+	conf := &config{
+		Signaling:  ts.URL,
+		ICEPolicy:  policyHost,
+		ICEServers: []iceServer{{URLs: []string{"stun:" + stunServer(t)}}},
+	}
+	if err := conf.validate(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	ok := doctor(&buf, conf)
+	report := buf.String()
+	for _, want := range []string{"signaling  " + ts.URL + "  ok", "mapped 127.0.0.1:", "nat", "none", "verdict"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+	if !ok {
+		t.Errorf("doctor failed:\n%s", report)
+	}
+}
+
+func TestDoctorSignalingDown(t *testing.T) {
+	ts := httptest.NewServer(signaling.NewServer())
+	ts.Close()
+	conf := &config{Signaling: ts.URL, ICEServers: []iceServer{{URLs: []string{"stun:" + stunServer(t)}}}}
+	if err := conf.validate(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if doctor(&buf, conf) {
+		t.Errorf("doctor passed without a signaling server:\n%s", buf.String())
+	}
+}
+
+func TestClassifyNAT(t *testing.T) {
+	addr := func(s string) *net.UDPAddr {
+		a, err := net.ResolveUDPAddr("udp", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	local := []net.IP{net.ParseIP("192.0.2.2")}
+	for _, tt := range []struct {
+		mapped []*net.UDPAddr
+		want   string
+	}{
+		{[]*net.UDPAddr{nil, nil}, natBlocked},
+		{[]*net.UDPAddr{addr("192.0.2.2:4000"), nil}, natNone},
+		{[]*net.UDPAddr{addr("198.51.100.1:4000"), nil}, natUnknown},
+		{[]*net.UDPAddr{addr("198.51.100.1:4000"), addr("198.51.100.1:4000")}, natCone},
+		{[]*net.UDPAddr{addr("198.51.100.1:4000"), addr("198.51.100.1:4001")}, natSymmetric},
+	} {
+		if got := classifyNAT(tt.mapped, local); got != tt.want {
+			t.Errorf("classifyNAT(%v) = %s, want %s", tt.mapped, got, tt.want)
+		}
+	}
+}
+
+func TestGatherCandidates(t *testing.T) {
+	gather := func(conf *config) []string {
+		t.Helper()
+		if err := conf.validate(); err != nil {
+			t.Fatal(err)
+		}
+		sdp, err := gatherCandidates(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var addrs []string
+		for _, line := range strings.Split(sdp, "\n") {
+			if fields := strings.Fields(line); strings.HasPrefix(line, "a=candidate:") && len(fields) >= 6 {
+				addrs = append(addrs, net.JoinHostPort(fields[4], fields[5]))
+			}
+		}
+		return addrs
+	}
+	addrs := gather(&config{ICEPolicy: policyHost, ICENetwork: "udp4", ICEPorts: "50100-50109"})
+	if len(addrs) == 0 {
+		t.Skip("no IPv4 interface besides loopback")
+	}
+	for _, a := range addrs {
+		host, port, _ := net.SplitHostPort(a)
+		if net.ParseIP(host).To4() == nil || port < "50100" || port > "50109" {
+			t.Errorf("candidate %s outside udp4 ports 50100-50109", a)
+		}
+	}
+	ip, _, _ := net.SplitHostPort(addrs[0])
+	for _, a := range gather(&config{ICEPolicy: policyHost, ICENetwork: "udp4", ICEExclude: []string{ip}}) {
+		if strings.HasPrefix(a, ip+":") {
+			t.Errorf("candidate %s on an excluded address", a)
+		}
+	}
+}
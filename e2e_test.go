@@ -0,0 +1,796 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nobonobo/ssh-p2p/p2p"
+	"github.com/nobonobo/ssh-p2p/signaling"
+	"github.com/nobonobo/ssh-p2p/turn"
+	"golang.org/x/crypto/ssh"
+)
+
+// e2eTimeout bounds each exchange of the end-to-end tests.
+const e2eTimeout = 30 * time.Second
+
+// harness runs both peers in process against a signaling server on
+// loopback, so no test reaches the network.
+type harness struct {
+	t     *testing.T
+	url   string      // of the signaling server
+	pulls chan string // the paths of the pulls made on it
+}
+
+// newHarness starts a signaling server and a server peer for rules, with
+// the configuration in effect edited by edit. Unless edit says otherwise,
+// ICE only offers host candidates and the relay is off, so that sessions
+// go through ICE.
+func newHarness(t *testing.T, rules []serverRule, edit func(*config)) *harness {
+	t.Helper()
+	pulled := make(chan struct{})
+	var once sync.Once
+	h := &harness{t: t, pulls: make(chan string, 64)}
+	srv := signaling.NewServer()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/pull/") {
+			once.Do(func() { close(pulled) })
+			select {
+			case h.pulls <- r.URL.Path:
+			default:
+			}
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	h.url = ts.URL
+	t.Cleanup(ts.Close)
+
+	conf := &config{
+		Signaling:    ts.URL,
+		ICEPolicy:    policyHost,
+		RelayTimeout: "0",
+		State:        filepath.Join(t.TempDir(), "keys.json"),
+		Servers:      rules,
+	}
+	if edit != nil {
+		edit(conf)
+	}
+	if err := conf.validate(); err != nil {
+		t.Fatal(err)
+	}
+	current.Store(conf)
+	t.Cleanup(func() { current.Store(&config{}) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		closeSessions(t)
+	})
+	keys := make([]string, 0, len(conf.Servers))
+	byKey := map[string]serverRule{}
+	for _, r := range conf.Servers {
+		keys = append(keys, r.Key)
+		byKey[r.Key] = r
+	}
+	if len(keys) > 0 {
+		go serve(ctx, conf.signalingClient(), keys, func() map[string]serverRule { return byKey })
+		select {
+		case <-pulled:
+		case <-time.After(e2eTimeout):
+			t.Fatal("server peer never pulled its offers")
+		}
+	}
+	return h
+}
+
+// options returns the options of the p2p library reaching the signaling
+// server of h with the ICE settings of the server peer.
+func (h *harness) options() *p2p.Options {
+	h.t.Helper()
+	rtcConf, err := currentConfig().rtcConfiguration()
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return &p2p.Options{Signaling: h.url, Configuration: rtcConf}
+}
+
+// listen listens on key with the p2p library and waits for the listener to
+// pull its offers.
+func (h *harness) listen(key string) net.Listener {
+	h.t.Helper()
+	l, err := p2p.Listen(context.Background(), key, h.options())
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	h.t.Cleanup(func() { l.Close() })
+	for {
+		select {
+		case path := <-h.pulls:
+			if strings.Contains(path, key) {
+				return l
+			}
+		case <-time.After(e2eTimeout):
+			h.t.Fatal("listener never pulled its offers")
+		}
+	}
+}
+
+// closeSessions closes the sessions a test left open and waits for them to
+// go.
+func closeSessions(t *testing.T) {
+	for _, s := range sessions.list() {
+		s.Close()
+	}
+	select {
+	case <-sessions.drained():
+	case <-time.After(e2eTimeout):
+		t.Error("sessions left open")
+	}
+}
+
+// dial connects a client peer for key and returns the application end of
+// its tunnel, a TCP connection as ssh would make.
+func (h *harness) dial(key string) *net.TCPConn {
+	h.t.Helper()
+	return h.connect(context.Background(), clientRule{Key: key})
+}
+
+// connect connects a client peer for rule, which gives up on the session
+// once ctx is done, and returns the application end of its tunnel.
+func (h *harness) connect(ctx context.Context, rule clientRule) *net.TCPConn {
+	h.t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	defer l.Close()
+	app, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	sock, err := l.Accept()
+	if err != nil {
+		app.Close()
+		h.t.Fatal(err)
+	}
+	h.t.Cleanup(func() { app.Close() })
+	go connect(ctx, rule, sock)
+	return app.(*net.TCPConn)
+}
+
+// wantEnd checks that the tunnel of conn ends without passing data, as
+// when either peer rejects the session.
+func wantEnd(t *testing.T, conn *net.TCPConn) {
+	t.Helper()
+	conn.SetDeadline(time.Now().Add(e2eTimeout))
+	if n, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("read %d bytes, %v; want the end of the stream", n, err)
+	}
+}
+
+// echoServer accepts connections and writes back what it reads until the
+// peer half-closes. It returns its address and a channel receiving each
+// connection once it is over.
+func echoServer(t *testing.T) (string, <-chan struct{}) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	done := make(chan struct{}, 16)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(c, c)
+				c.(*net.TCPConn).CloseWrite()
+				io.Copy(io.Discard, c)
+				c.Close()
+				done <- struct{}{}
+			}()
+		}
+	}()
+	return l.Addr().String(), done
+}
+
+// turnServer serves TURN for alice on loopback over UDP and TCP, where the
+// relays of both peers reach each other, and returns its URL for network.
+func turnServer(t *testing.T, network string) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := turn.NewServer(conn, turn.Config{Users: map[string]string{"alice": "secret"}, AllowPrivatePeers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	go s.ServeListener(l)
+	t.Cleanup(func() { s.Close() })
+	if network == "tcp" {
+		return "turn:" + l.Addr().String() + "?transport=tcp"
+	}
+	return "turn:" + conn.LocalAddr().String()
+}
+
+// useTURN returns an edit relaying sessions through a TURN server reached
+// over network, and only through it.
+func useTURN(network string) func(*testing.T, *config) {
+	return func(t *testing.T, c *config) {
+		c.ICEPolicy = policyRelay
+		c.ICEServers = []iceServer{{URLs: []string{turnServer(t, network)}, Username: "alice", Credential: "secret"}}
+		c.ICENetwork = "udp4"
+	}
+}
+
+// unusedAddr returns a loopback address nothing listens on.
+func unusedAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+// waitServed waits until n sessions, counting both peers, were started.
+func waitServed(t *testing.T, n int) {
+	t.Helper()
+	for end := time.Now().Add(e2eTimeout); sessions.served() < n; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(end) {
+			t.Fatalf("%d sessions started, want %d", sessions.served(), n)
+		}
+	}
+}
+
+// idleConn extends the deadline of conn before each read and write, so
+// that slow runs, as under the race detector, only fail when stalled.
+type idleConn struct {
+	conn *net.TCPConn
+}
+
+func (c idleConn) Read(b []byte) (int, error) {
+	c.conn.SetReadDeadline(time.Now().Add(e2eTimeout))
+	return c.conn.Read(b)
+}
+
+func (c idleConn) Write(b []byte) (int, error) {
+	c.conn.SetWriteDeadline(time.Now().Add(e2eTimeout))
+	return c.conn.Write(b)
+}
+
+// echo sends n random bytes through conn, half-closes it and checks that
+// exactly the same bytes come back before the end of the stream.
+func echo(conn *net.TCPConn, n int) error {
+	want := make([]byte, n)
+	rand.Read(want)
+	c := idleConn{conn}
+	sent := make(chan error, 1)
+	go func() {
+		var err error
+		for b := want; len(b) > 0 && err == nil; {
+			k := min(len(b), 32<<10)
+			_, err = c.Write(b[:k])
+			b = b[k:]
+		}
+		if err == nil {
+			err = conn.CloseWrite()
+		}
+		sent <- err
+	}()
+	got, err := io.ReadAll(c)
+	if err != nil {
+		return fmt.Errorf("read after %d of %d bytes: %w", len(got), n, err)
+	}
+	if err := <-sent; err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("got %d bytes back, want the %d sent", len(got), n)
+	}
+	return nil
+}
+
+func TestTransfer(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		edit func(*testing.T, *config)
+	}{
+		{"host", nil},
+		// Peers offering no candidates go through the relay right away.
+		{"relay", func(t *testing.T, c *config) {
+			c.RelayTimeout = "2s"
+			c.ICEExclude = []string{"0.0.0.0/0", "::/0"}
+		}},
+		{"turn", useTURN("udp")},
+		{"turn-tcp", useTURN("tcp")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			addr, done := echoServer(t)
+			key := uuid.New().String()
+			h := newHarness(t, []serverRule{{Key: key, Dial: addr}}, func(c *config) {
+				if tc.edit != nil {
+					tc.edit(t, c)
+				}
+			})
+			if err := echo(h.dial(key), 1<<20); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case <-done:
+			case <-time.After(e2eTimeout):
+				t.Fatal("target connection left open")
+			}
+		})
+	}
+}
+
+func TestConcurrentSessions(t *testing.T) {
+	addr, _ := echoServer(t)
+	key := uuid.New().String()
+	h := newHarness(t, []serverRule{{Key: key, Dial: addr}}, nil)
+	const n = 4
+	errs := make(chan error, n)
+	served := sessions.served()
+	for i := 0; i < n; i++ {
+		conn := h.dial(key)
+		// An offer pushed while the server peer is between two pulls is
+		// dropped, so each is answered before the next is made.
+		waitServed(t, served+2*(i+1))
+		go func(size int) { errs <- echo(conn, size) }(64<<10 + i)
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestTeardown(t *testing.T) {
+	addr, done := echoServer(t)
+	key := uuid.New().String()
+	h := newHarness(t, []serverRule{{Key: key, Dial: addr}}, nil)
+	conn := h.dial(key)
+	if err := echo(conn, 1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(e2eTimeout):
+		t.Fatal("target connection left open")
+	}
+	select {
+	case <-sessions.drained():
+	case <-time.After(e2eTimeout):
+		t.Fatalf("%d sessions left open", len(sessions.list()))
+	}
+}
+
+func TestDialFailure(t *testing.T) {
+	key := uuid.New().String()
+	h := newHarness(t, []serverRule{{Key: key, Dial: unusedAddr(t)}}, nil)
+	conn := h.dial(key)
+	conn.SetDeadline(time.Now().Add(e2eTimeout))
+	if n, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("read %d bytes, %v; want the end of the stream", n, err)
+	}
+}
+
+func TestExpiredKey(t *testing.T) {
+	key := signaling.Key{ID: uuid.New().String(), Expires: time.Now().Add(-time.Minute)}.String()
+	h := newHarness(t, nil, nil)
+	conn := h.dial(key)
+	conn.SetDeadline(time.Now().Add(e2eTimeout))
+	if n, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("read %d bytes, %v; want the end of the stream", n, err)
+	}
+}
+
+// syncBuffer is a buffer safe for the concurrent writes of a logger.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// captureLog sends the log of the test to the returned buffer.
+func captureLog(t *testing.T) *syncBuffer {
+	t.Helper()
+	buf := &syncBuffer{}
+	if err := setLogger(buf, logConfig{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { setLogger(os.Stderr, logConfig{}) })
+	return buf
+}
+
+func TestMalformedKey(t *testing.T) {
+	log := captureLog(t)
+	h := newHarness(t, nil, nil)
+	conn := h.dial(uuid.New().String() + ".x1")
+	conn.SetDeadline(time.Now().Add(e2eTimeout))
+	if n, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("read %d bytes, %v; want the end of the stream", n, err)
+	}
+	if got := log.String(); !strings.Contains(got, "unknown limit") || strings.Contains(got, errKeyExpired.Error()) {
+		t.Errorf("log of a malformed key: %s", got)
+	}
+}
+
+func TestControl(t *testing.T) {
+	key := uuid.New().String()
+	newHarness(t, []serverRule{{Key: key, Dial: unusedAddr(t)}}, nil)
+	c, err := dialControl(clientRule{Key: key}, e2eTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.conn.Close()
+	if _, err := c.ping(100); err != nil {
+		t.Fatal(err)
+	}
+	n, _, err := c.upload(1024, 100*time.Millisecond)
+	if err != nil || n == 0 || n%1024 != 0 {
+		t.Fatalf("upload: %d bytes, %v", n, err)
+	}
+	n, _, err = c.download(1024, 100*time.Millisecond)
+	if err != nil || n == 0 || n%1024 != 0 {
+		t.Fatalf("download: %d bytes, %v", n, err)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	log := captureLog(t)
+	addr, _ := echoServer(t)
+	key := uuid.New().String()
+	h := newHarness(t, []serverRule{{Key: key, Dial: addr}}, nil)
+	c := idleConn{h.dial(key)}
+	buf := make([]byte, 1)
+	if _, err := c.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(c, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// With no time to drain, both peers' sessions are closed before the
+	// summary.
+	newDaemon(context.Background()).shutdown(0, nil)
+	if n := len(sessions.list()); n != 0 {
+		t.Errorf("%d sessions left after shutdown", n)
+	}
+	if got := log.String(); !strings.Contains(got, "closed=2") {
+		t.Errorf("shutdown log: %s", got)
+	}
+	if n, err := c.Read(buf); err != io.EOF {
+		t.Fatalf("read %d bytes, %v; want the end of the stream", n, err)
+	}
+}
+
+func TestReloadFailure(t *testing.T) {
+	dir := t.TempDir()
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	clients := func(addrs ...string) []clientRule {
+		var rules []clientRule
+		for _, a := range addrs {
+			rules = append(rules, clientRule{Key: uuid.New().String(), Listen: a})
+		}
+		return rules
+	}
+	load := func(log string, rules []clientRule) *config {
+		c := &config{
+			Log:     logConfig{File: filepath.Join(dir, log), Level: "info"},
+			State:   filepath.Join(dir, "keys.json"),
+			Clients: rules,
+		}
+		if err := c.validate(); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	t.Cleanup(func() {
+		current.Store(&config{})
+		setLogger(os.Stderr, logConfig{})
+	})
+	d := newDaemon(context.Background())
+	defer d.shutdown(0, nil)
+
+	first := unusedAddr(t)
+	old := load("old.log", clients(first))
+	if err := d.apply(old); err != nil {
+		t.Fatal(err)
+	}
+	// The reload binds a new address before failing on a busy one.
+	added := unusedAddr(t)
+	if err := d.apply(load("new.log", clients(first, added, busy.Addr().String()))); err == nil {
+		t.Fatal("reload onto a busy address succeeded")
+	}
+	if currentConfig() != old {
+		t.Error("failed reload published its config")
+	}
+	slog.Info("after the reload")
+	if b, _ := os.ReadFile(filepath.Join(dir, "old.log")); !strings.Contains(string(b), "after the reload") {
+		t.Errorf("old log after a failed reload: %s", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "new.log")); len(b) != 0 {
+		t.Errorf("new log of a failed reload: %s", b)
+	}
+	if l, err := net.Listen("tcp", added); err != nil {
+		t.Errorf("failed reload left %s bound: %v", added, err)
+	} else {
+		l.Close()
+	}
+	if l, err := net.Listen("tcp", first); err == nil {
+		l.Close()
+		t.Errorf("failed reload stopped listening on %s", first)
+	}
+}
+
+// TestLibrary connects each peer of the command to one of the p2p library,
+// which runs the same handshake.
+func TestLibrary(t *testing.T) {
+	t.Run("dial", func(t *testing.T) {
+		addr, done := echoServer(t)
+		key := uuid.New().String()
+		h := newHarness(t, []serverRule{{Key: key, Dial: addr}}, nil)
+		ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+		defer cancel()
+		c, err := p2p.Dial(ctx, key, h.options())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		c.SetDeadline(time.Now().Add(e2eTimeout))
+		if _, err := c.Write([]byte("ping")); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.(*p2p.Conn).CloseWrite(); err != nil {
+			t.Fatal(err)
+		}
+		if b, err := io.ReadAll(c); err != nil || string(b) != "ping" {
+			t.Fatalf("read %q, %v; want the echo of ping", b, err)
+		}
+		select {
+		case <-done:
+		case <-time.After(e2eTimeout):
+			t.Fatal("target connection left open")
+		}
+	})
+	t.Run("listen", func(t *testing.T) {
+		key := uuid.New().String()
+		h := newHarness(t, nil, nil)
+		l := h.listen(key)
+		conn := h.dial(key)
+		c, err := l.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		go func() {
+			io.Copy(c, c)
+			c.(*p2p.Conn).CloseWrite()
+		}()
+		if err := echo(conn, 64<<10); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestInvitePin(t *testing.T) {
+	addr, _ := echoServer(t)
+	key := uuid.New().String()
+	id, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHarness(t, []serverRule{{Key: key, Dial: addr}}, func(c *config) { c.identity = id })
+	dial := func(pub *ecdsa.PublicKey) *net.TCPConn {
+		pin, err := identityPin(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inv := &invite{Version: inviteVersion, Key: key, Signaling: h.url, Pin: pin}
+		return h.connect(context.Background(), clientRule{invite: inv})
+	}
+
+	if err := echo(dial(&id.PublicKey), 1024); err != nil {
+		t.Fatal(err)
+	}
+	// An invite pinning another identity, as one for a server the
+	// signaling server does not route to, fails the server's proof.
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEnd(t, dial(&other.PublicKey))
+}
+
+func TestCode(t *testing.T) {
+	log := captureLog(t)
+	addr, _ := echoServer(t)
+	const code = "7-crossword-puzzle"
+	key := uuid.New().String()
+	h := newHarness(t, []serverRule{{Key: key, Dial: addr, code: code}}, nil)
+	if err := echo(h.connect(context.Background(), clientRule{Key: key, code: code}), 1024); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server's answer fails the key confirmation of a wrong code, and
+	// the server burns the code once the attempt times out.
+	wantEnd(t, h.connect(context.Background(), clientRule{Key: key, code: "7-wrong-guess"}))
+	for end := time.Now().Add(e2eTimeout); !strings.Contains(log.String(), "code burned"); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(end) {
+			t.Fatal("failed attempt did not burn the code")
+		}
+	}
+	// The server drops the offers of a burned code unanswered.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	wantEnd(t, h.connect(ctx, clientRule{Key: key, code: code}))
+	if got := log.String(); !strings.Contains(got, errCodeBurned.Error()) {
+		t.Errorf("log of an offer with a burned code: %s", got)
+	}
+}
+
+// writeKey writes a new SSH private key to a file and returns its path and
+// public key.
+func writeKey(t *testing.T) (string, ssh.PublicKey) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "id_ecdsa")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, pub
+}
+
+func TestAuthorizedKeys(t *testing.T) {
+	addr, _ := echoServer(t)
+	allowed, allowedPub := writeKey(t)
+	elsewhere, elsewherePub := writeKey(t)
+	unknown, _ := writeKey(t)
+	authorized := filepath.Join(t.TempDir(), "authorized_keys")
+	lines := fmt.Sprintf("permitopen=%q %s", addr, ssh.MarshalAuthorizedKey(allowedPub)) +
+		fmt.Sprintf("permitopen=%q %s", unusedAddr(t), ssh.MarshalAuthorizedKey(elsewherePub))
+	if err := os.WriteFile(authorized, []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	key := uuid.New().String()
+	h := newHarness(t, []serverRule{{Key: key, Dial: addr, AuthorizedKeys: authorized}}, nil)
+	dial := func(sshKey string) *net.TCPConn {
+		return h.connect(context.Background(), clientRule{Key: key, SSHKey: sshKey})
+	}
+
+	if err := echo(dial(allowed), 1024); err != nil {
+		t.Fatal(err)
+	}
+	// A key not listed, and one whose permitopen names another target.
+	wantEnd(t, dial(unknown))
+	wantEnd(t, dial(elsewhere))
+	// Without a key the server waits for the auth notice in vain.
+	wantEnd(t, h.dial(key))
+}
+
+func TestConfirmSAS(t *testing.T) {
+	log := captureLog(t)
+	addr, _ := echoServer(t)
+	key := uuid.New().String()
+	h := newHarness(t, []serverRule{{Key: key, Dial: addr, Confirm: true}}, nil)
+	defer func(f func(key, peer, sas string) (bool, error)) { confirmSAS = f }(confirmSAS)
+	for _, ok := range []bool{true, false} {
+		shown := make(chan string, 1)
+		confirmSAS = func(_, _, sas string) (bool, error) {
+			shown <- sas
+			return ok, nil
+		}
+		conn := h.dial(key)
+		var sas string
+		select {
+		case sas = <-shown:
+		case <-time.After(e2eTimeout):
+			t.Fatal("SAS never shown")
+		}
+		if !ok {
+			wantEnd(t, conn)
+			continue
+		}
+		if err := echo(conn, 1024); err != nil {
+			t.Fatal(err)
+		}
+		// The client peer shows the same SAS in its log.
+		if !strings.Contains(log.String(), fmt.Sprintf("sas=%q pair=", sas)) {
+			t.Errorf("SAS %q not in the log of the client: %s", sas, log)
+		}
+	}
+}
+
+func TestMapKeys(t *testing.T) {
+	log := captureLog(t)
+	var rules ruleFlags
+	keys := []string{uuid.New().String(), uuid.New().String()}
+	var done []<-chan struct{}
+	for _, key := range keys {
+		addr, d := echoServer(t)
+		done = append(done, d)
+		if err := rules.Set(key + "=" + addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := newHarness(t, rules, nil)
+	for i, key := range keys {
+		if err := echo(h.dial(key), 1024); err != nil {
+			t.Fatal(err)
+		}
+		// Only the target of key served the session.
+		select {
+		case <-done[i]:
+		case <-time.After(e2eTimeout):
+			t.Fatalf("target of key %d not dialed", i)
+		}
+		select {
+		case <-done[1-i]:
+			t.Fatalf("key %d reached the target of key %d", i, 1-i)
+		default:
+		}
+	}
+	// The offer for a key the daemon does not serve stays unanswered.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	wantEnd(t, h.connect(ctx, clientRule{Key: uuid.New().String()}))
+	if n := strings.Count(log.String(), "offer received"); n != len(keys) {
+		t.Errorf("%d offers received, want %d", n, len(keys))
+	}
+}
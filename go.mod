@@ -1,9 +1,27 @@
 module github.com/nobonobo/ssh-p2p
 
+go 1.27.1
+
 require (
-	github.com/google/go-cmp v0.2.0 // indirect
+	github.com/BurntSushi/toml v0.3.1
 	github.com/google/uuid v1.0.0
-	github.com/pions/transport v0.1.0 // indirect
+	github.com/pions/pkg v0.0.0-20181115215726-b60cd756f712
 	github.com/pions/webrtc v1.2.0
+	golang.org/x/crypto v0.0.0-20181203042331-505ab145d0a9
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/google/go-cmp v0.2.0 // indirect
+	github.com/pions/dtls v1.0.2 // indirect
+	github.com/pions/transport v0.1.0 // indirect
+	github.com/pkg/errors v0.8.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/testify v1.2.2 // indirect
+	golang.org/x/net v0.0.0-20181220203305-927f97764cc3 // indirect
 	gotest.tools v2.2.0+incompatible // indirect
 )
+
+replace github.com/pions/webrtc => ./third_party/webrtc
+
+replace github.com/pions/dtls => ./third_party/dtls
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pions/webrtc"
+)
+
+// The server peer has a long-term identity key which it uses for its DTLS
+// certificates. pions issues a new self-signed certificate whenever one is
+// created, so it is the key rather than the certificate that invite tokens
+// pin. pions also only verifies the certificate of the DTLS server, which is
+// the offering client peer, so the server peer proves possession of its key
+// by signing the client's verified DTLS fingerprint; see proveIdentity.
+
+var errIdentityMismatch = errors.New("server identity mismatch")
+
+// defaultIdentityPath returns the identity key file used when none is set.
+func defaultIdentityPath() string {
+	return filepath.Join(filepath.Dir(defaultStatePath()), "identity.pem")
+}
+
+// loadIdentity reads the PEM encoded ECDSA key at path, creating it on
+// first use.
+func loadIdentity(path string) (*ecdsa.PrivateKey, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+		b = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+		return key, os.WriteFile(path, b, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "EC PRIVATE KEY" {
+		return nil, fmt.Errorf("%s: no EC PRIVATE KEY block", path)
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// identityPin returns the pin of a public key as written in invite tokens.
+func identityPin(pub *ecdsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return "sha256:" + base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// identityCertificate returns a DTLS certificate for key.
+func identityCertificate(key *ecdsa.PrivateKey) (webrtc.RTCCertificate, error) {
+	cert, err := webrtc.GenerateCertificate(key)
+	if err != nil {
+		return webrtc.RTCCertificate{}, err
+	}
+	return *cert, nil
+}
+
+func proofDigest(fingerprint string) []byte {
+	sum := sha256.Sum256([]byte("ssh-p2p proof\n" + fingerprint))
+	return sum[:]
+}
+
+// proveIdentity returns the arguments of a proof notice binding key to the
+// peer's DTLS fingerprint.
+func proveIdentity(key *ecdsa.PrivateKey, peerFingerprint string) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	sig, err := ecdsa.SignASN1(rand.Reader, key, proofDigest(peerFingerprint))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(der) + " " + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// verifyIdentity checks the arguments of a proof notice against the pinned
+// key and the local DTLS fingerprint.
+func verifyIdentity(args, pin, localFingerprint string) error {
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		return errIdentityMismatch
+	}
+	der, err := base64.RawURLEncoding.DecodeString(fields[0])
+	if err != nil {
+		return errIdentityMismatch
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(fields[1])
+	if err != nil {
+		return errIdentityMismatch
+	}
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return errIdentityMismatch
+	}
+	key, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return errIdentityMismatch
+	}
+	if p, err := identityPin(key); err != nil || p != pin {
+		return errIdentityMismatch
+	}
+	if !ecdsa.VerifyASN1(key, proofDigest(localFingerprint), sig) {
+		return errIdentityMismatch
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nobonobo/ssh-p2p/signaling"
+)
+
+const (
+	invitePrefix  = "p2p://"
+	inviteVersion = 1
+)
+
+// invite bundles everything a client peer needs to reach a server peer. Its
+// text form is invitePrefix followed by the unpadded base64url encoding of
+// the JSON object, so it can be pasted wherever a key is accepted.
+type invite struct {
+	Version    int         `json:"v"`
+	Key        string      `json:"k"`
+	Signaling  string      `json:"s,omitempty"`
+	ICEServers []iceServer `json:"i,omitempty"`
+	Pin        string      `json:"p,omitempty"` // see identityPin
+}
+
+// isInvite reports whether s is an invite token rather than a bare key.
+func isInvite(s string) bool {
+	return strings.HasPrefix(s, invitePrefix)
+}
+
+func (inv *invite) String() string {
+	b, err := json.Marshal(inv)
+	if err != nil {
+		panic(err)
+	}
+	return invitePrefix + base64.RawURLEncoding.EncodeToString(b)
+}
+
+// parseInvite parses the text form of an invite token.
+func parseInvite(s string) (*invite, error) {
+	if !isInvite(s) {
+		return nil, fmt.Errorf("invite: missing %s prefix", invitePrefix)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, invitePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invite: %v", err)
+	}
+	var inv invite
+	if err := json.Unmarshal(b, &inv); err != nil {
+		return nil, fmt.Errorf("invite: %v", err)
+	}
+	if inv.Version != inviteVersion {
+		return nil, fmt.Errorf("invite: unsupported version %d", inv.Version)
+	}
+	if _, err := signaling.ParseKey(inv.Key); err != nil {
+		return nil, fmt.Errorf("invite: %v", err)
+	}
+	c := config{Signaling: inv.Signaling, ICEServers: inv.ICEServers}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invite: %v", err)
+	}
+	return &inv, nil
+}
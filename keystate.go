@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/nobonobo/ssh-p2p/signaling"
+)
+
+var (
+	errKeyExpired = errors.New("key expired")
+	errKeyRevoked = errors.New("key revoked")
+	errKeyUsedUp  = errors.New("key used up")
+)
+
+// keyState is the persistent record of revoked keys and of the sessions
+// started with use-limited keys. The server reads it for every offer, so a
+// revoke from another process takes effect without a reload.
+type keyState struct {
+	Revoked map[string]time.Time `json:"revoked"` // by key id
+	Used    map[string]int       `json:"used"`    // by key id
+}
+
+var keyStateMu sync.Mutex
+
+// defaultStatePath returns the key state file used when none is configured.
+func defaultStatePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "ssh-p2p", "keys.json")
+}
+
+func loadKeyState(path string) (*keyState, error) {
+	st := &keyState{Revoked: map[string]time.Time{}, Used: map[string]int{}}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, st); err != nil {
+		return nil, err
+	}
+	if st.Revoked == nil {
+		st.Revoked = map[string]time.Time{}
+	}
+	if st.Used == nil {
+		st.Used = map[string]int{}
+	}
+	return st, nil
+}
+
+func (st *keyState) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// checkKey checks that key may start another session, without recording
+// a use.
+func checkKey(path, key string) error {
+	return spendKey(path, key, false)
+}
+
+// useKey checks that key may start another session and records the use.
+// Sessions call it once the peer is authenticated and the channel is open,
+// so offers that fail or never connect do not use up the key.
+func useKey(path, key string) error {
+	return spendKey(path, key, true)
+}
+
+func spendKey(path, key string, use bool) error {
+	k, err := signaling.ParseKey(key)
+	if err != nil {
+		return err
+	}
+	if k.Expired(time.Now()) {
+		return errKeyExpired
+	}
+	keyStateMu.Lock()
+	defer keyStateMu.Unlock()
+	st, err := loadKeyState(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := st.Revoked[k.ID]; ok {
+		return errKeyRevoked
+	}
+	if k.Uses == 0 {
+		return nil
+	}
+	if st.Used[k.ID] >= k.Uses {
+		return errKeyUsedUp
+	}
+	if !use {
+		return nil
+	}
+	st.Used[k.ID]++
+	return st.save(path)
+}
+
+// revokeKey marks every key sharing the id of key as revoked.
+func revokeKey(path, key string) error {
+	k, err := signaling.ParseKey(key)
+	if err != nil {
+		return err
+	}
+	keyStateMu.Lock()
+	defer keyStateMu.Unlock()
+	st, err := loadKeyState(path)
+	if err != nil {
+		return err
+	}
+	st.Revoked[k.ID] = time.Now()
+	return st.save(path)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyUses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	key := "6ee87ebb-2938-47f9-8577-e8fd4aa3988c.u1"
+	for i := 0; i < 3; i++ {
+		if err := checkKey(path, key); err != nil {
+			t.Fatalf("check %d: %v", i, err)
+		}
+	}
+	if err := useKey(path, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkKey(path, key); !errors.Is(err, errKeyUsedUp) {
+		t.Fatalf("check after the last use: %v", err)
+	}
+	if err := useKey(path, key); !errors.Is(err, errKeyUsedUp) {
+		t.Fatalf("use after the last use: %v", err)
+	}
+	if err := revokeKey(path, "6ee87ebb-2938-47f9-8577-e8fd4aa3988c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkKey(path, "6ee87ebb-2938-47f9-8577-e8fd4aa3988c"); !errors.Is(err, errKeyRevoked) {
+		t.Fatalf("check of a revoked key: %v", err)
+	}
+	if err := checkKey(path, "6ee87ebb-2938-47f9-8577-e8fd4aa3988c.e1"); !errors.Is(err, errKeyExpired) {
+		t.Fatalf("check of an expired key: %v", err)
+	}
+}
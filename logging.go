@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// logLevel is the minimum level of the default logger. It is a variable so
+// that a reload can change it for loggers that were already derived.
+var logLevel = new(slog.LevelVar)
+
+// logHandler is the handler of the default logger. Loggers derived with
+// With keep it, so a reload swaps what it writes to rather than the
+// default logger.
+var logHandler = &swapHandler{root: new(atomic.Pointer[slog.Handler])}
+
+// logFlags holds the -log-level and -log-format values, used where the
+// config file does not set them.
+var logFlags logConfig
+
+// setLogger makes lc the configuration of the default logger writing to w.
+func setLogger(w io.Writer, lc logConfig) error {
+	h, l, err := newLogHandler(w, lc)
+	if err != nil {
+		return err
+	}
+	installLogger(h, l)
+	return nil
+}
+
+// newLogHandler returns the handler and level lc describes for w.
+func newLogHandler(w io.Writer, lc logConfig) (slog.Handler, slog.Level, error) {
+	level, format := lc.Level, lc.Format
+	if level == "" {
+		level = logFlags.Level
+	}
+	if format == "" {
+		format = logFlags.Format
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, 0, err
+	}
+	opts := &slog.HandlerOptions{
+		AddSource: true,
+		Level:     logLevel,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if src, ok := a.Value.Any().(*slog.Source); ok && a.Key == slog.SourceKey {
+				a.Value = slog.StringValue(fmt.Sprintf("%s:%d", filepath.Base(src.File), src.Line))
+			}
+			return a
+		},
+	}
+	var h slog.Handler
+	switch format {
+	case "", "text":
+		h = slog.NewTextHandler(w, opts)
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, 0, fmt.Errorf("unknown log format %q", format)
+	}
+	return h, l, nil
+}
+
+// installLogger makes h, at level l, the handler of the default logger.
+func installLogger(h slog.Handler, l slog.Level) {
+	logLevel.Set(l)
+	logHandler.root.Store(&h)
+	slog.SetDefault(slog.New(logHandler))
+}
+
+// swapHandler passes records on to the handler stored in root, applying
+// the attributes and groups it was derived with.
+type swapHandler struct {
+	root   *atomic.Pointer[slog.Handler]
+	derive []func(slog.Handler) slog.Handler
+	// cache is the handler derived from the root it was derived for.
+	cache atomic.Pointer[derivedHandler]
+}
+
+type derivedHandler struct {
+	root *slog.Handler
+	h    slog.Handler
+}
+
+// handler returns the current root handler with the derivations of h.
+func (h *swapHandler) handler() slog.Handler {
+	root := h.root.Load()
+	if c := h.cache.Load(); c != nil && c.root == root {
+		return c.h
+	}
+	d := *root
+	for _, f := range h.derive {
+		d = f(d)
+	}
+	h.cache.Store(&derivedHandler{root, d})
+	return d
+}
+
+func (h *swapHandler) with(f func(slog.Handler) slog.Handler) *swapHandler {
+	derive := append(h.derive[:len(h.derive):len(h.derive)], f)
+	return &swapHandler{root: h.root, derive: derive}
+}
+
+func (h *swapHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler().Enabled(ctx, level)
+}
+
+func (h *swapHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler().Handle(ctx, r)
+}
+
+func (h *swapHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(d slog.Handler) slog.Handler { return d.WithAttrs(attrs) })
+}
+
+func (h *swapHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(d slog.Handler) slog.Handler { return d.WithGroup(name) })
+}
+
+// fatal logs msg at error level and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// sdpValue is an SDP blob that is only logged in full at debug level.
+type sdpValue string
+
+// LogValue implements slog.LogValuer.
+func (s sdpValue) LogValue() slog.Value {
+	if logLevel.Level() <= slog.LevelDebug {
+		return slog.StringValue(string(s))
+	}
+	return slog.StringValue(fmt.Sprintf("[redacted %d bytes]", len(s)))
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLoggerReload(t *testing.T) {
+	defer setLogger(&bytes.Buffer{}, logConfig{})
+	var before, after bytes.Buffer
+	if err := setLogger(&before, logConfig{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.With("session", "s1").WithGroup("g")
+	if err := setLogger(&after, logConfig{Level: "info", Format: "json"}); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello", "n", 1)
+	if before.Len() != 0 {
+		t.Errorf("derived logger wrote to the old handler: %s", before.String())
+	}
+	if got := after.String(); !strings.Contains(got, `"session":"s1"`) || !strings.Contains(got, `"g":{"n":1}`) {
+		t.Errorf("derived logger after reload wrote %q", got)
+	}
+}
@@ -1,124 +1,74 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"net"
-	"net/http"
 	"os"
 	"os/signal"
-	"path"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nobonobo/ssh-p2p/p2p"
 	"github.com/nobonobo/ssh-p2p/signaling"
-	"github.com/pions/webrtc"
-	"github.com/pions/webrtc/pkg/datachannel"
-	"github.com/pions/webrtc/pkg/ice"
+	"github.com/nobonobo/ssh-p2p/turn"
 )
 
 const usage = `Usage: ssh-p2p SUBCMD [options]
 sub-commands:
-	newkey
-		new generate key of connection
-	server -key="..." [-dial="127.0.0.1:22"]
-		ssh server side peer mode
+	newkey [-ttl=1h] [-uses=1] [-invite [-config="..."] [-identity="..."]]
+		new generate key of connection, optionally expiring or limited
+		to a number of sessions; with -invite print an invite token
+		carrying the signaling and ICE settings and the server identity
+	revoke -key="..." [-state="..."]
+		revoke a key on the server side peer
+	server -key="..." [-dial="127.0.0.1:22"] [-confirm]
+		ssh server side peer mode, -confirm asks before forwarding each
+		session until its short authentication string is confirmed
+	server -map="KEY1=127.0.0.1:22" -map="KEY2=127.0.0.1:5900" ...
+		serve several keys from one process
+	server ... -authorized-keys=$HOME/.ssh/authorized_keys
+		dial only for clients signing with one of the SSH keys, honoring
+		restrict, no-port-forwarding, permitopen, from and expiry-time
+	server -code [-dial="127.0.0.1:22"]
+		serve a new short code such as 7-crossword-puzzle instead of a key
+	server -config="ssh-p2p.toml"
+		ssh server side peer mode with [[server]] rules of config file
 	client -key="..." [-listen="127.0.0.1:2222"]
-		ssh client side peer mode
+		ssh client side peer mode, -key also accepts an invite token
+	client ... -ssh-key=agent|$HOME/.ssh/id_ed25519
+		authorize with the keys of ssh-agent or a private key file
+	client -code="7-crossword-puzzle" [-listen="127.0.0.1:2222"]
+		connect with a short code printed by server -code
+	client -config="ssh-p2p.toml"
+		ssh client side peer mode with [[client]] rules of config file
+	ping -key="..." [-count=4] [-size=64] [-interval=1s]
+		measure the round-trip time to the server peer, which answers
+		itself on a reserved control channel without dialing its target
+	bench -key="..." [-size=1024,8192,65536] [-duration=5s]
+		measure the throughput to and from the server peer per message size
+	doctor [-config="ssh-p2p.toml"] [-key="invite token"]
+		check the signaling server, STUN and TURN servers, the NAT and
+		ICE candidates, and tell whether sessions are likely to connect
+	turn-server [-listen=":3478"] [-users=users.txt] [-relay-ip="..."]
+		answer STUN binding requests and, for the name:password lines of
+		-users, relay as a TURN server; peers use it with
+		-ice="stun:host:3478" or -ice="turn:name:password@host:3478"
+	turn-server ... -listen-tls=":443" -tls-cert=cert.pem -tls-key=key.pem
+		also serve TURN over TLS (turns:host:443) for UDP-blocked clients
+send SIGHUP to reload the config file, SIGINT or SIGTERM to drain and exit.
 `
 
 var (
-	defaultRTCConfiguration = webrtc.RTCConfiguration{
-		IceServers: []webrtc.RTCIceServer{
-			{
-				URLs: []string{
-					"stun:stun.l.google.com:19302",
-				},
-			},
-		},
-	}
+	defaultRTCConfiguration = p2p.DefaultConfiguration
 )
 
-func push(dst, src, sdp string) error {
-	buf := bytes.NewBuffer(nil)
-	if err := json.NewEncoder(buf).Encode(signaling.ConnectInfo{
-		Source: src,
-		SDP:    sdp,
-	}); err != nil {
-		return err
-	}
-	resp, err := http.Post(signaling.URI+path.Join("/", "push", dst), "application/json", buf)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("http failed")
-	}
-	return nil
-}
-
-func pull(ctx context.Context, id string) <-chan signaling.ConnectInfo {
-	ch := make(chan signaling.ConnectInfo)
-	var retry time.Duration
-	go func() {
-		faild := func() {
-			if retry < 10 {
-				retry++
-			}
-			time.Sleep(retry * time.Second)
-		}
-		defer close(ch)
-		for {
-			req, err := http.NewRequest("GET", signaling.URI+path.Join("/", "pull", id), nil)
-			if err != nil {
-				if ctx.Err() == context.Canceled {
-					return
-				}
-				log.Println("get failed:", err)
-				faild()
-				continue
-			}
-			req = req.WithContext(ctx)
-			res, err := http.DefaultClient.Do(req)
-			if err != nil {
-				if ctx.Err() == context.Canceled {
-					return
-				}
-				log.Println("get failed:", err)
-				faild()
-				continue
-			}
-			defer res.Body.Close()
-			retry = time.Duration(0)
-			var info signaling.ConnectInfo
-			if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
-				if err == io.EOF {
-					continue
-				}
-				if ctx.Err() == context.Canceled {
-					return
-				}
-				log.Println("get failed:", err)
-				faild()
-				continue
-			}
-			if len(info.Source) > 0 && len(info.SDP) > 0 {
-				ch <- info
-			}
-		}
-	}()
-	return ch
-}
-
 func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	cmd := ""
 	if len(os.Args) > 1 {
 		cmd = os.Args[1]
@@ -131,193 +81,676 @@ func main() {
 		os.Exit(1)
 	}
 
+	var conf, state, identity string
+	var drain time.Duration
+	flags.StringVar(&conf, "config", "", "config file path")
+	flags.StringVar(&state, "state", "", "key state file (server/revoke, default "+defaultStatePath()+")")
+	flags.StringVar(&identity, "identity", "", "server identity key file (server/newkey, default "+defaultIdentityPath()+")")
+	flags.DurationVar(&drain, "drain-timeout", 30*time.Second, "wait for live sessions on shutdown (server/client)")
+	flags.DurationVar(&relayFlag, "relay-timeout", relayFlag, "fall back to the signaling relay when no direct connection opens in time, 0 to never (server/client)")
+	flags.StringVar(&policyFlag, "ice-policy", policyFlag, "ICE transport policy: all, relay to only connect through TURN relays without revealing addresses, or host to skip STUN (server/client)")
+	flags.StringVar(&networkFlag, "ice-network", networkFlag, "network of ICE candidates: udp, udp4 or udp6 (server/client)")
+	flags.Var(&interfaceFlag, "ice-interface", "only gather ICE candidates on this interface name or CIDR (repeatable, server/client)")
+	flags.Var(&excludeFlag, "ice-exclude", "never gather ICE candidates on this interface name or CIDR (repeatable, server/client)")
+	flags.StringVar(&portsFlag, "ice-ports", "", "local UDP port range of ICE candidates such as 50000-50099 (server/client, default any)")
+	flags.Var(&iceFlag, "ice", "ICE server URL such as stun:host:3478 or turn:name:password@host:3478 (repeatable, used when the config sets no ice_servers)")
+	flags.StringVar(&signalingFlag.Proxy, "signaling-proxy", "", "HTTP proxy URL of the signaling server, with user:password@ for basic auth (default from HTTPS_PROXY)")
+	flags.Var((*listFlags)(&signalingFlag.CA), "signaling-ca", "PEM bundle trusted for the signaling server besides the system roots (repeatable)")
+	flags.StringVar(&signalingFlag.Cert, "signaling-cert", "", "PEM client certificate presented to the signaling server")
+	flags.StringVar(&signalingFlag.Key, "signaling-key", "", "PEM private key of -signaling-cert")
+	flags.Var((*listFlags)(&signalingFlag.Pins), "signaling-pin", "sha256: pin of a public key the signaling server certificate chain must hold (repeatable)")
+	flags.StringVar(&signalingFlag.Token, "signaling-token", "", "API token of a tenant of the signaling server")
+	flags.StringVar(&logFlags.Level, "log-level", "info", "log level: debug, info, warn or error")
+	flags.StringVar(&logFlags.Format, "log-format", "text", "log format: text or json")
 	switch cmd {
 	default:
 		flags.Usage()
 	case "newkey":
-		key := uuid.New().String()
-		fmt.Println(key)
+		var ttl time.Duration
+		var uses int
+		var inviteToken bool
+		flags.DurationVar(&ttl, "ttl", 0, "key lifetime, 0 for no expiry")
+		flags.IntVar(&uses, "uses", 0, "number of sessions the key is valid for, 0 for unlimited")
+		flags.BoolVar(&inviteToken, "invite", false, "print an invite token instead of a bare key")
+		if err := flags.Parse(os.Args[2:]); err != nil {
+			fatal("invalid arguments", err)
+		}
+		if err := setLogger(os.Stderr, logConfig{}); err != nil {
+			fatal("invalid arguments", err)
+		}
+		key := signaling.Key{ID: uuid.New().String(), Uses: uses}
+		if ttl > 0 {
+			key.Expires = time.Now().Add(ttl)
+		}
+		if !inviteToken {
+			fmt.Println(key)
+			os.Exit(0)
+		}
+		c := &config{}
+		if conf != "" {
+			var err error
+			if c, err = loadConfig(conf); err != nil {
+				fatal("invalid config", err)
+			}
+		}
+		if identity != "" {
+			c.Identity = identity
+		}
+		id, err := loadIdentity(c.identityPath())
+		if err != nil {
+			fatal("identity failed", err)
+		}
+		pin, err := identityPin(&id.PublicKey)
+		if err != nil {
+			fatal("identity failed", err)
+		}
+		inv := &invite{
+			Version:    inviteVersion,
+			Key:        key.String(),
+			Signaling:  c.Signaling,
+			ICEServers: c.iceServers(),
+			Pin:        pin,
+		}
+		fmt.Println(inv)
 		os.Exit(0)
+	case "ping", "bench":
+		var key, sshKey, sizes string
+		var count, size int
+		var interval, duration, timeout time.Duration
+		flags.StringVar(&key, "key", "sample", "connection key or invite token")
+		flags.StringVar(&sshKey, "ssh-key", "", `SSH key to authorize with, "agent" or a private key file`)
+		flags.DurationVar(&timeout, "timeout", time.Minute, "time to wait for the session")
+		if cmd == "ping" {
+			flags.IntVar(&count, "count", 4, "pings to send, 0 until interrupted")
+			flags.IntVar(&size, "size", 64, "bytes per ping")
+			flags.DurationVar(&interval, "interval", time.Second, "time between pings")
+		} else {
+			flags.StringVar(&sizes, "size", "1024,8192,65536", "message sizes, comma separated")
+			flags.DurationVar(&duration, "duration", 5*time.Second, "time measured per size and direction")
+		}
+		if err := flags.Parse(os.Args[2:]); err != nil {
+			fatal("invalid arguments", err)
+		}
+		if err := setLogger(os.Stderr, logConfig{}); err != nil {
+			fatal("invalid arguments", err)
+		}
+		if size < 0 || size > maxControlFrame {
+			fatal("invalid arguments", fmt.Errorf("invalid ping size %d", size))
+		}
+		benchSizes, err := parseSizes(sizes)
+		if cmd == "bench" && err != nil {
+			fatal("invalid arguments", err)
+		}
+		c := &config{}
+		if conf != "" {
+			if c, err = loadConfig(conf); err != nil {
+				fatal("invalid config", err)
+			}
+		}
+		// The rule is never listened on: the control channel is spoken to
+		// over a pipe.
+		c.Clients = []clientRule{{Key: key, Listen: "127.0.0.1:0", SSHKey: sshKey}}
+		c.Servers = nil
+		if err := c.validate(); err != nil {
+			fatal("invalid arguments", err)
+		}
+		current.Store(c)
+		cc, err := dialControl(c.Clients[0], timeout)
+		if err != nil {
+			fatal(cmd+" failed", err)
+		}
+		if cmd == "ping" {
+			// An interrupted ping still prints its summary.
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			err = runPing(ctx, cc, os.Stdout, count, size, interval)
+			stop()
+		} else {
+			err = runBench(cc, os.Stdout, benchSizes, duration)
+		}
+		cc.conn.Close()
+		if err != nil {
+			fatal(cmd+" failed", err)
+		}
+	case "doctor":
+		var key string
+		flags.StringVar(&key, "key", "", "invite token whose signaling and ICE servers to check")
+		if err := flags.Parse(os.Args[2:]); err != nil {
+			fatal("invalid arguments", err)
+		}
+		if err := setLogger(os.Stderr, logConfig{}); err != nil {
+			fatal("invalid arguments", err)
+		}
+		c := &config{}
+		if conf != "" {
+			var err error
+			if c, err = loadConfig(conf); err != nil {
+				fatal("invalid config", err)
+			}
+		} else if err := c.validate(); err != nil {
+			fatal("invalid arguments", err)
+		}
+		if key != "" {
+			inv, err := parseInvite(key)
+			if err != nil {
+				fatal("invalid arguments", err)
+			}
+			c = clientRule{invite: inv}.settings(c)
+		}
+		if !doctor(os.Stdout, c) {
+			os.Exit(1)
+		}
+	case "turn-server":
+		var listen turnListen
+		var relayIP, ports, users string
+		tc := turn.Config{}
+		flags.StringVar(&listen.udp, "listen", ":3478", "listen addr = host:port (udp)")
+		flags.StringVar(&listen.tcp, "listen-tcp", "", "listen addr = host:port for TURN over TCP (default off)")
+		flags.StringVar(&listen.tls, "listen-tls", "", "listen addr = host:port for TURN over TLS such as :443 (default off)")
+		flags.StringVar(&listen.cert, "tls-cert", "", "PEM certificate chain of -listen-tls")
+		flags.StringVar(&listen.key, "tls-key", "", "PEM private key of -listen-tls")
+		flags.StringVar(&users, "users", "", "file of name:password lines allowed to allocate relays (default none, STUN only)")
+		flags.StringVar(&tc.Realm, "realm", "ssh-p2p", "realm of the long-term credentials")
+		flags.StringVar(&relayIP, "relay-ip", "", "address advertised for relays (default the -listen host)")
+		flags.StringVar(&ports, "ports", "", "port range of relays such as 49152-65535 (default any)")
+		flags.BoolVar(&tc.AllowPrivatePeers, "allow-private-peers", false, "relay to loopback, private and link-local addresses and to this host")
+		flags.IntVar(&tc.Quota, "quota", 10, "relays a user may hold at a time, 0 for unlimited")
+		flags.DurationVar(&tc.DefaultLifetime, "lifetime", turn.DefaultLifetime, "default relay lifetime")
+		flags.DurationVar(&tc.MaxLifetime, "max-lifetime", turn.MaxLifetime, "maximum relay lifetime")
+		if err := flags.Parse(os.Args[2:]); err != nil {
+			fatal("invalid arguments", err)
+		}
+		if err := setLogger(os.Stderr, logConfig{}); err != nil {
+			fatal("invalid arguments", err)
+		}
+		var err error
+		if tc.PortMin, tc.PortMax, err = parsePorts(ports); err != nil {
+			fatal("invalid arguments", err)
+		}
+		if relayIP != "" {
+			if tc.RelayIP = net.ParseIP(relayIP); tc.RelayIP == nil {
+				fatal("invalid arguments", fmt.Errorf("invalid relay address %q", relayIP))
+			}
+		}
+		if users != "" {
+			if tc.Users, err = loadUsers(users); err != nil {
+				fatal("invalid arguments", err)
+			}
+		}
+		runTURN(listen, tc)
+	case "revoke":
+		var key string
+		flags.StringVar(&key, "key", "", "connection key")
+		if err := flags.Parse(os.Args[2:]); err != nil {
+			fatal("invalid arguments", err)
+		}
+		if err := setLogger(os.Stderr, logConfig{}); err != nil {
+			fatal("invalid arguments", err)
+		}
+		c := &config{State: state}
+		if conf != "" {
+			var err error
+			if c, err = loadConfig(conf); err != nil {
+				fatal("invalid config", err)
+			}
+			if state != "" {
+				c.State = state
+			}
+		}
+		if err := revokeKey(c.statePath(), key); err != nil {
+			fatal("revoke failed", err)
+		}
+		slog.Info("revoked", "key", key, "state", c.statePath())
 	case "server":
-		var addr, key string
+		var addr, key, authorizedKeys, dialTimeout string
+		var rules ruleFlags
+		var withCode, confirm bool
 		flags.StringVar(&addr, "dial", "127.0.0.1:22", "dial addr = host:port")
 		flags.StringVar(&key, "key", "sample", "connection key")
+		flags.Var(&rules, "map", "key=host:port (repeatable, replaces -key and -dial)")
+		flags.BoolVar(&withCode, "code", false, "serve a new short code instead of -key")
+		flags.BoolVar(&confirm, "confirm", false, "ask on the terminal to confirm the SAS of each session")
+		flags.StringVar(&authorizedKeys, "authorized-keys", "", "authorized_keys file of the SSH keys clients must sign with")
+		flags.StringVar(&dialTimeout, "dial-timeout", "", "timeout of the target dial (default 10s)")
 		if err := flags.Parse(os.Args[2:]); err != nil {
-			log.Fatalln(err)
-		}
-		sig := make(chan os.Signal, 1)
-		signal.Notify(sig, syscall.SIGINT)
-		ctx, cancel := context.WithCancel(context.Background())
-		go serve(ctx, key, addr)
-		<-sig
-		cancel()
+			fatal("invalid arguments", err)
+		}
+		if err := setLogger(os.Stderr, logConfig{}); err != nil {
+			fatal("invalid arguments", err)
+		}
+		var code string
+		if withCode {
+			var err error
+			if code, err = newCode(); err != nil {
+				fatal("code failed", err)
+			}
+			if key, err = parseCode(code); err != nil {
+				fatal("code failed", err)
+			}
+			fmt.Println(code)
+		}
+		run(func() (*config, error) {
+			if code != "" {
+				c := &config{State: state, Identity: identity}
+				if conf != "" {
+					var err error
+					if c, err = loadConfig(conf); err != nil {
+						return nil, err
+					}
+				}
+				c.Servers = []serverRule{{Key: key, Dial: addr, Confirm: confirm, AuthorizedKeys: authorizedKeys, DialTimeout: dialTimeout}}
+				c.Clients = nil
+				if err := c.validate(); err != nil {
+					return nil, err
+				}
+				c.Servers[0].code = code
+				return c, nil
+			}
+			if conf == "" {
+				c := &config{Servers: rules, State: state, Identity: identity}
+				if len(rules) == 0 {
+					c.Servers = []serverRule{{Key: key, Dial: addr}}
+				}
+				for i := range c.Servers {
+					c.Servers[i].Confirm = confirm
+					c.Servers[i].AuthorizedKeys = authorizedKeys
+					c.Servers[i].DialTimeout = dialTimeout
+				}
+				return c, c.validate()
+			}
+			c, err := loadConfig(conf)
+			if err != nil {
+				return nil, err
+			}
+			if state != "" {
+				c.State = state
+			}
+			if identity != "" {
+				c.Identity = identity
+			}
+			c.Clients = nil
+			return c, nil
+		}, drain)
 	case "client":
-		var addr, key string
+		var addr, key, code, sshKey string
 		flags.StringVar(&addr, "listen", "127.0.0.1:2222", "listen addr = host:port")
+		flags.StringVar(&sshKey, "ssh-key", "", `SSH key to authorize with, "agent" or a private key file`)
 		flags.StringVar(&key, "key", "sample", "connection key")
+		flags.StringVar(&code, "code", "", "short code printed by server -code, instead of -key")
 		if err := flags.Parse(os.Args[2:]); err != nil {
-			log.Fatalln(err)
+			fatal("invalid arguments", err)
 		}
-		l, err := net.Listen("tcp", addr)
-		if err != nil {
-			log.Fatalln(err)
-		}
-		log.Println("listen:", addr)
-		sig := make(chan os.Signal, 1)
-		signal.Notify(sig, syscall.SIGINT)
-		ctx, cancel := context.WithCancel(context.Background())
-		go func() {
-			for {
-				sock, err := l.Accept()
-				if err != nil {
-					log.Println(err)
-					continue
+		if err := setLogger(os.Stderr, logConfig{}); err != nil {
+			fatal("invalid arguments", err)
+		}
+		if code != "" {
+			var err error
+			if key, err = parseCode(code); err != nil {
+				fatal("invalid arguments", err)
+			}
+		}
+		run(func() (*config, error) {
+			if code != "" {
+				c := &config{}
+				if conf != "" {
+					var err error
+					if c, err = loadConfig(conf); err != nil {
+						return nil, err
+					}
 				}
-				go connect(ctx, key, sock)
+				c.Clients = []clientRule{{Key: key, Listen: addr, SSHKey: sshKey}}
+				c.Servers = nil
+				if err := c.validate(); err != nil {
+					return nil, err
+				}
+				c.Clients[0].code = code
+				return c, nil
+			}
+			if conf == "" {
+				c := &config{Clients: []clientRule{{Key: key, Listen: addr, SSHKey: sshKey}}}
+				return c, c.validate()
+			}
+			c, err := loadConfig(conf)
+			if err != nil {
+				return nil, err
 			}
-		}()
-		<-sig
-		cancel()
+			c.Servers = nil
+			return c, nil
+		}, drain)
 	}
 }
 
-type sendWrap struct {
-	*webrtc.RTCDataChannel
+// candidateTypes returns the types of the ICE candidates listed in sdp,
+// such as "host" or "host|srflx", or "none".
+func candidateTypes(sdp string) string {
+	seen := map[string]bool{}
+	var types []string
+	for _, line := range strings.Split(sdp, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "a=candidate:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		for i := 6; i+1 < len(fields); i++ {
+			if fields[i] == "typ" && !seen[fields[i+1]] {
+				seen[fields[i+1]] = true
+				types = append(types, fields[i+1])
+			}
+		}
+	}
+	if len(types) == 0 {
+		return "none"
+	}
+	sort.Strings(types)
+	return strings.Join(types, "|")
 }
 
-func (s *sendWrap) Write(b []byte) (int, error) {
-	err := s.RTCDataChannel.Send(datachannel.PayloadBinary{Data: b})
-	return len(b), err
+// allowed reports whether every candidate address offered in sdp is
+// permitted by allow.
+func allowed(allow allowList, sdp string) bool {
+	if len(allow) == 0 {
+		return true
+	}
+	ips := p2p.CandidateIPs(sdp)
+	if len(ips) == 0 {
+		return false
+	}
+	for _, ip := range ips {
+		if !allow.Contains(ip) {
+			return false
+		}
+	}
+	return true
 }
 
-func serve(ctx context.Context, key, addr string) {
-	log.Println("server started")
-	for v := range pull(ctx, key) {
-		log.Printf("info: %#v", v)
-		pc, err := webrtc.New(defaultRTCConfiguration)
-		if err != nil {
-			log.Println("rtc error:", err)
+// serve pulls the offers for all keys through a single signaling request and
+// answers each one with the rule of its destination key.
+func serve(ctx context.Context, client *signaling.Client, keys []string, rules func() map[string]serverRule) {
+	logger := slog.With("keys", strings.Join(keys, ","))
+	logger.Info("server started")
+	for v := range client.Pull(ctx, logger, keys...) {
+		rule, ok := rules()[v.Destination]
+		if !ok {
+			logger.Warn("unknown key", "key", v.Destination, "session", v.Source)
 			continue
 		}
-		ssh, err := net.Dial("tcp", addr)
+		answer(rule, v)
+	}
+}
+
+// answer accepts the offer v and forwards its stream to rule.Dial.
+func answer(rule serverRule, v signaling.ConnectInfo) {
+	key, addr := rule.Key, rule.Dial
+	logger := slog.With("key", key, "session", v.Source)
+	logger.Info("offer received", "sdp", sdpValue(v.SDP))
+	if !allowed(rule.allow, v.SDP) {
+		logger.Warn("offer rejected", "reason", "candidate not allowed")
+		return
+	}
+	switch v.Channel {
+	case "", controlChannel:
+	default:
+		logger.Warn("offer rejected", "reason", "unknown channel "+v.Channel)
+		return
+	}
+	if rule.MaxSessions > 0 && sessions.count(key) >= rule.MaxSessions {
+		logger.Warn("offer rejected", "reason", "too many sessions")
+		return
+	}
+	if err := checkKey(currentConfig().statePath(), key); err != nil {
+		logger.Warn("offer rejected", "reason", err)
+		return
+	}
+	var kcA, kcB []byte
+	var share string
+	if rule.code != "" {
+		if err := codes.acquire(key); err != nil {
+			logger.Warn("offer rejected", "reason", err)
+			return
+		}
+		p, err := newPAKE(rule.code, false)
+		if err == nil {
+			kcA, kcB, err = p.Finish(v.PAKE)
+		}
 		if err != nil {
-			log.Println("ssh dial filed:", err)
-			pc.Close()
-			continue
+			codes.release(key, false)
+			logger.Warn("offer rejected", "reason", err)
+			return
+		}
+		share = p.Message()
+	}
+	conf := currentConfig()
+	rtcConf, err := conf.rtcConfiguration()
+	if err != nil {
+		logger.Error("rtc error", "err", err)
+		if rule.code != "" {
+			codes.release(key, false)
 		}
-		pc.OnICEConnectionStateChange(func(state ice.ConnectionState) {
-			log.Print("pc ice state change:", state)
-			if state == ice.ConnectionStateDisconnected {
-				pc.Close()
-				ssh.Close()
+		return
+	}
+	s := &session{key: key, log: logger}
+	sessions.add(s)
+	opts := &p2p.Options{
+		Signaling:     conf.signalingURI(),
+		HTTPClient:    conf.httpClient,
+		Configuration: rtcConf,
+		Logger:        slog.Default(),
+		RelayAfter:    conf.relayAfter(),
+		KeepCandidate: conf.keepCandidate,
+		Handshake: func(ps *p2p.Session) (*p2p.Handshake, error) {
+			if err := s.setPeer(ps); err != nil {
+				return nil, err
 			}
-		})
-		pc.OnDataChannel(func(dc *webrtc.RTCDataChannel) {
-			//dc.Lock()
-			dc.OnOpen(func() {
-				log.Print("dial:", addr)
-				io.Copy(&sendWrap{dc}, ssh)
-				log.Println("disconnected")
-			})
-			dc.Onmessage(func(payload datachannel.Payload) {
-				switch p := payload.(type) {
-				case *datachannel.PayloadBinary:
-					_, err := ssh.Write(p.Data)
+			if rule.AuthorizedKeys != "" {
+				ps.Expect(noticeAuth, func(args string) error {
+					data := authData(ps.Fingerprints())
+					ak, err := checkAuth(rule.AuthorizedKeys, addr, ps.PeerIP(), args, data)
 					if err != nil {
-						log.Println("ssh write failed:", err)
-						pc.Close()
-						return
+						return err
 					}
+					s.log.Info("authorized", "ssh_key", ak.fingerprint(), "comment", ak.comment)
+					return nil
+				})
+			}
+			if rule.code != "" {
+				ps.Expect(noticeProof, func(args string) error {
+					offerFP, answerFP := ps.Fingerprints()
+					return checkConfirmation(kcA, offerFP, answerFP, args)
+				})
+			}
+			return &p2p.Handshake{
+				Answer: func(offer, answer *signaling.ConnectInfo) error {
+					if rule.code != "" {
+						answer.PAKE = share
+						answer.Confirm = confirmFingerprints(kcB, confirmBinding(offer.SDP, offer.Relay), confirmBinding(answer.SDP, answer.Relay))
+					}
+					return nil
+				},
+				Open: func() error {
+					if conf.identity == nil {
+						return nil
+					}
+					offerFP, _ := ps.Fingerprints()
+					proof, err := proveIdentity(conf.identity, offerFP)
+					if err != nil {
+						return err
+					}
+					return ps.Notify(noticeProof + " " + proof)
+				},
+			}, nil
+		},
+	}
+	if rule.code != "" {
+		opts.OpenTimeout = codeTimeout
+	}
+	// The target is only dialed once the client peer passed every check of
+	// the handshake; data arriving before that is buffered by the stream.
+	go func() {
+		st, err := p2p.Answer(context.Background(), v, opts)
+		if rule.code != "" {
+			codes.release(key, err == nil)
+			if err != nil {
+				s.log.Warn("code burned, restart the server for a new code", "err", err)
+			}
+		}
+		if err != nil {
+			s.Close()
+			return
+		}
+		if err := s.setStream(st); err != nil {
+			return
+		}
+		if rule.Confirm {
+			sas := st.(*p2p.Conn).Session().SAS()
+			ok, err := confirmSAS(key, v.Source, sas)
+			if err != nil || !ok {
+				s.log.Warn("session refused", "sas", sas, "err", err)
+				s.Close()
+				return
+			}
+		}
+		if v.Channel == controlChannel {
+			local, remote := net.Pipe()
+			go func() {
+				if err := serveControl(remote, s.log); err != nil {
+					s.log.Warn("control failed", "err", err)
 				}
-			})
-			//dc.Unlock()
-		})
-		if err := pc.SetRemoteDescription(webrtc.RTCSessionDescription{
-			Type: webrtc.RTCSdpTypeOffer,
-			Sdp:  string(v.SDP),
-		}); err != nil {
-			log.Println("rtc error:", err)
-			pc.Close()
-			ssh.Close()
-			continue
+			}()
+			if err := s.setConn(local); err != nil {
+				return
+			}
+			s.connected("channel", controlChannel)
+			s.pipe()
+			s.log.Info("disconnected")
+			return
+		}
+		// Only a session about to forward uses up the key: control
+		// sessions, such as ping and bench, do not.
+		if err := useKey(conf.statePath(), key); err != nil {
+			s.log.Warn("session refused", "reason", err)
+			s.notify(noticeError + " " + err.Error())
+			s.Close()
+			return
 		}
-		answer, err := pc.CreateAnswer(nil)
+		ssh, err := net.DialTimeout("tcp", addr, rule.dialTimeout)
 		if err != nil {
-			log.Println("rtc error:", err)
-			pc.Close()
-			ssh.Close()
-			continue
+			s.log.Error("dial failed", "addr", addr, "err", err)
+			s.notify(noticeError + " " + err.Error())
+			s.Close()
+			return
 		}
-		if err := push(v.Source, key, answer.Sdp); err != nil {
-			log.Println("rtc error:", err)
-			pc.Close()
-			ssh.Close()
-			continue
+		if err := s.setConn(ssh); err != nil {
+			return
 		}
-	}
+		s.connected("addr", addr)
+		s.pipe()
+		s.log.Info("disconnected")
+	}()
 }
 
-func connect(ctx context.Context, key string, sock net.Conn) {
-	id := uuid.New().String()
-	log.Println("client id:", id)
-	pc, err := webrtc.New(defaultRTCConfiguration)
+func connect(ctx context.Context, rule clientRule, sock net.Conn) {
+	key, conf := rule.key(), rule.settings(currentConfig())
+	logger := slog.With("key", key)
+	k, err := signaling.ParseKey(key)
+	if err == nil && k.Expired(time.Now()) {
+		err = errKeyExpired
+	}
 	if err != nil {
-		log.Println("rtc error:", err)
+		logger.Error("invalid key", "from", sock.RemoteAddr(), "err", err)
+		sock.Close()
 		return
 	}
-	pc.OnICEConnectionStateChange(func(state ice.ConnectionState) {
-		log.Print("pc ice state change:", state)
-	})
-	dc, err := pc.CreateDataChannel("data", nil)
+	rtcConf, err := conf.rtcConfiguration()
 	if err != nil {
-		log.Println("create dc failed:", err)
-		pc.Close()
+		logger.Error("rtc error", "err", err)
+		sock.Close()
 		return
 	}
-	//dc.Lock()
-	dc.OnOpen(func() {
-		io.Copy(&sendWrap{dc}, sock)
-		pc.Close()
-		log.Println("disconnected")
-	})
-	dc.OnMessage(func(payload datachannel.Payload) {
-		switch p := payload.(type) {
-		case *datachannel.PayloadBinary:
-			_, err := sock.Write(p.Data)
-			if err != nil {
-				log.Println("sock write failed:", err)
-				pc.Close()
-				return
-			}
+	var pk *pake
+	if rule.code != "" {
+		if pk, err = newPAKE(rule.code, true); err != nil {
+			logger.Error("pake failed", "err", err)
+			sock.Close()
+			return
 		}
-	})
-	//dc.Unlock()
-	log.Print("DataChannel:", dc)
-	go func() {
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-		for v := range pull(ctx, id) {
-			log.Printf("info: %#v", v)
-			if err := pc.SetRemoteDescription(webrtc.RTCSessionDescription{
-				Type: webrtc.RTCSdpTypeAnswer,
-				Sdp:  string(v.SDP),
-			}); err != nil {
-				log.Println("rtc error:", err)
-				pc.Close()
-				return
+	}
+	var s *session
+	opts := &p2p.Options{
+		Signaling:     conf.signalingURI(),
+		HTTPClient:    conf.httpClient,
+		Configuration: rtcConf,
+		Logger:        slog.Default(),
+		RelayAfter:    conf.relayAfter(),
+		KeepCandidate: conf.keepCandidate,
+		Handshake: func(ps *p2p.Session) (*p2p.Handshake, error) {
+			s = &session{key: key, conn: sock, log: ps.Logger()}
+			sessions.add(s)
+			s.setPeer(ps)
+			s.log.Info("connecting", "from", sock.RemoteAddr())
+			if pin := rule.pin(); pin != "" {
+				ps.Expect(noticeProof, func(args string) error {
+					offerFP, _ := ps.Fingerprints()
+					return verifyIdentity(args, pin, offerFP)
+				})
 			}
+			var kcA []byte
+			return &p2p.Handshake{
+				Offer: func(offer *signaling.ConnectInfo) error {
+					if pk != nil {
+						offer.PAKE = pk.Message()
+					}
+					return nil
+				},
+				Answer: func(offer, answer *signaling.ConnectInfo) error {
+					s.log.Info("answer received", "sdp", sdpValue(answer.SDP))
+					if pk == nil {
+						return nil
+					}
+					kc, kcB, err := pk.Finish(answer.PAKE)
+					if err == nil {
+						err = checkConfirmation(kcB, confirmBinding(offer.SDP, offer.Relay), confirmBinding(answer.SDP, answer.Relay), answer.Confirm)
+					}
+					if err != nil {
+						return fmt.Errorf("code check failed: %w", err)
+					}
+					kcA = kc
+					return nil
+				},
+				Open: func() error {
+					offerFP, answerFP := ps.Fingerprints()
+					if kcA != nil {
+						if err := ps.Notify(noticeProof + " " + confirmFingerprints(kcA, offerFP, answerFP)); err != nil {
+							return err
+						}
+					}
+					if rule.SSHKey == "" {
+						return nil
+					}
+					args, err := signAuth(rule.SSHKey, authData(offerFP, answerFP))
+					if err != nil {
+						return fmt.Errorf("ssh key failed: %w", err)
+					}
+					return ps.Notify(noticeAuth + " " + args)
+				},
+			}, nil
+		},
+	}
+	if rule.control {
+		opts.Channel = controlChannel
+	}
+	st, err := p2p.Dial(ctx, key, opts)
+	if err != nil {
+		if s == nil {
+			logger.Error("connect failed", "err", err)
+			sock.Close()
 			return
 		}
-	}()
-	offer, err := pc.CreateOffer(nil)
-	if err != nil {
-		log.Println("create offer error:", err)
-		pc.Close()
+		s.Close()
 		return
 	}
-	if err := push(key, id, offer.Sdp); err != nil {
-		log.Println("push error:", err)
-		pc.Close()
+	if err := s.setStream(st); err != nil {
 		return
 	}
+	s.connected()
+	s.pipe()
+	s.log.Info("disconnected")
 }
@@ -0,0 +1,490 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pions/webrtc"
+	"github.com/pions/webrtc/pkg/datachannel"
+)
+
+const (
+	// noticeOpen is the first message on a stream. A side only considers
+	// the stream open once it received the peer's, since pions can lose
+	// the messages opening a DataChannel without either side noticing.
+	noticeOpen = "open"
+	// noticeClose is the message telling the peer that no more data
+	// follows, so that its reads return io.EOF.
+	noticeClose = "close"
+)
+
+// maxMessage is the largest message written. pions reads messages into a
+// buffer of that size and silently truncates longer ones.
+const maxMessage = 8192
+
+// maxBuffer bounds the data received but not yet read. Once it is full the
+// transport's receive loop waits, which pushes back on the peer.
+const maxBuffer = 1 << 20
+
+var errNotOpen = errors.New("p2p: channel not open")
+
+// Addr is the address of one end of a stream.
+type Addr struct {
+	Key     string // connection key
+	Session string // session id, empty for a listener
+}
+
+// Network implements net.Addr.
+func (a Addr) Network() string { return "p2p" }
+
+func (a Addr) String() string {
+	if a.Session == "" {
+		return a.Key
+	}
+	return a.Key + "/" + a.Session
+}
+
+// transport carries the messages of a stream: data, or notices.
+type transport interface {
+	send(notice bool, b []byte) error
+}
+
+// channel is the transport of a DataChannel, on which notices are string
+// messages and data binary ones.
+type channel struct{ dc *webrtc.RTCDataChannel }
+
+func (ch channel) send(notice bool, b []byte) error {
+	if notice {
+		return ch.dc.Send(datachannel.PayloadString{Data: b})
+	}
+	return ch.dc.Send(datachannel.PayloadBinary{Data: b})
+}
+
+// Conn is a stream over a DataChannel or a relay. Notices other than the
+// stream's own open and close are passed to the handler set by OnNotice.
+type Conn struct {
+	tr            transport
+	local, remote net.Addr
+	onClose       func()
+
+	mu       sync.Mutex
+	cond     *sync.Cond // signals changes of buf and the state below
+	buf      [][]byte
+	size     int
+	open     bool // the transport is open
+	ready    bool // the peer's open was received
+	eof      bool // the peer sent close
+	wclosed  bool // we sent close
+	closed   bool
+	err      error // the transport failed, see fail
+	onNotice func(string)
+	onOpen   func()
+	session  *Session // nil unless the stream of a session
+
+	done    chan struct{} // closed by Close
+	sending chan struct{} // held by the message being sent
+	rd, wd  deadline
+}
+
+func newConn(tr transport, local, remote net.Addr) *Conn {
+	c := &Conn{
+		tr:      tr,
+		local:   local,
+		remote:  remote,
+		done:    make(chan struct{}),
+		sending: make(chan struct{}, 1),
+	}
+	c.cond = sync.NewCond(&c.mu)
+	c.rd.init()
+	c.wd.init()
+	return c
+}
+
+// NewConn returns a stream over dc, which must not be open yet. The stream
+// can be written once the channel opens; see OnOpen.
+func NewConn(dc *webrtc.RTCDataChannel, local, remote net.Addr) *Conn {
+	c := newConn(channel{dc}, local, remote)
+	dc.OnOpen(c.opened)
+	dc.OnMessage(func(payload datachannel.Payload) {
+		switch p := payload.(type) {
+		case *datachannel.PayloadBinary:
+			c.receive(false, p.Data)
+		case *datachannel.PayloadString:
+			c.receive(true, p.Data)
+		}
+	})
+	return c
+}
+
+// opened is called once the transport is open. pions may deliver the
+// peer's open before, so the stream is only ready once both happened.
+func (c *Conn) opened() {
+	c.mu.Lock()
+	c.open = true
+	f := c.onOpen
+	run := c.ready && !c.closed
+	c.mu.Unlock()
+	c.tr.send(true, []byte(noticeOpen))
+	if f != nil && run {
+		go f()
+	}
+}
+
+// OnOpen sets a function called once both sides opened the stream, or
+// right away if they already did.
+func (c *Conn) OnOpen(f func()) {
+	c.mu.Lock()
+	c.onOpen = f
+	ready := c.open && c.ready
+	c.mu.Unlock()
+	if ready {
+		go f()
+	}
+}
+
+// Ready reports whether both sides opened the stream.
+func (c *Conn) Ready() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.open && c.ready
+}
+
+// OnNotice sets the handler of the peer's notices.
+func (c *Conn) OnNotice(f func(msg string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onNotice = f
+}
+
+// receive handles a message from the transport.
+func (c *Conn) receive(notice bool, b []byte) {
+	if !notice {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for c.size >= maxBuffer && !c.closed {
+			c.cond.Wait()
+		}
+		if c.closed {
+			return
+		}
+		c.buf = append(c.buf, b)
+		c.size += len(b)
+		c.cond.Broadcast()
+		return
+	}
+	msg := string(b)
+	c.mu.Lock()
+	switch msg {
+	case noticeOpen:
+		c.ready = true
+		f := c.onOpen
+		run := c.open && !c.closed // else opened runs f
+		c.mu.Unlock()
+		if f != nil && run {
+			// f may run for the life of the stream, and the transport
+			// must go on receiving meanwhile.
+			go f()
+		}
+		return
+	case noticeClose:
+		c.eof = true
+		c.cond.Broadcast()
+		c.mu.Unlock()
+		return
+	}
+	f := c.onNotice
+	c.mu.Unlock()
+	if f != nil {
+		f(msg)
+	}
+}
+
+// Read implements net.Conn.
+func (c *Conn) Read(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.buf) == 0 {
+		switch {
+		case c.closed:
+			return 0, net.ErrClosed
+		case c.eof:
+			return 0, io.EOF
+		case c.err != nil:
+			return 0, c.err
+		case c.rd.expired():
+			return 0, os.ErrDeadlineExceeded
+		}
+		c.waitOr(c.rd.wait())
+	}
+	n := copy(b, c.buf[0])
+	if n < len(c.buf[0]) {
+		c.buf[0] = c.buf[0][n:]
+	} else {
+		c.buf = c.buf[1:]
+	}
+	c.size -= n
+	c.cond.Broadcast()
+	return n, nil
+}
+
+// fail makes reads return err once the data received is read, unless the
+// peer closed its side already.
+func (c *Conn) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.cond.Broadcast()
+}
+
+// waitOr waits on cond, waking up early when stop is closed. c.mu is held.
+func (c *Conn) waitOr(stop <-chan struct{}) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			c.mu.Lock()
+			c.cond.Broadcast()
+			c.mu.Unlock()
+		case <-done:
+		}
+	}()
+	c.cond.Wait()
+}
+
+// Write implements net.Conn. A write the transport blocks fails once the
+// write deadline passes or the stream is closed, though the message being
+// sent then may still reach the peer.
+func (c *Conn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	closed, open := c.closed || c.wclosed, c.open
+	c.mu.Unlock()
+	switch {
+	case closed:
+		return 0, net.ErrClosed
+	case !open:
+		return 0, errNotOpen
+	case c.wd.expired():
+		return 0, os.ErrDeadlineExceeded
+	}
+	n := 0
+	for len(b) > 0 {
+		m := min(len(b), maxMessage)
+		if err := c.send(false, b[:m]); err != nil {
+			return n, err
+		}
+		n += m
+		b = b[m:]
+	}
+	return n, nil
+}
+
+// send sends a message once the previous one went out, giving up when the
+// write deadline passes or the stream is closed. The transport goes on
+// with a message given up on, so that the next ones follow it in order.
+func (c *Conn) send(notice bool, b []byte) error {
+	select {
+	case c.sending <- struct{}{}:
+	case <-c.wd.wait():
+		return os.ErrDeadlineExceeded
+	case <-c.done:
+		return net.ErrClosed
+	}
+	if c.wd.expired() {
+		<-c.sending
+		return os.ErrDeadlineExceeded
+	}
+	b = append([]byte(nil), b...) // the send may outlive the caller's buffer
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.tr.send(notice, b)
+		<-c.sending
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-c.wd.wait():
+		return os.ErrDeadlineExceeded
+	case <-c.done:
+		return net.ErrClosed
+	}
+}
+
+// Notify sends a notice to the peer. It is a no-op until the channel opens.
+func (c *Conn) Notify(msg string) error {
+	c.mu.Lock()
+	closed, open := c.closed, c.open
+	c.mu.Unlock()
+	if closed {
+		return net.ErrClosed
+	}
+	if !open {
+		return nil
+	}
+	return c.send(true, []byte(msg))
+}
+
+// CloseWrite tells the peer that no more data follows, so that its reads
+// return io.EOF once they drained the stream. Reads go on until the peer
+// closes its side.
+func (c *Conn) CloseWrite() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return net.ErrClosed
+	}
+	send := c.open && !c.wclosed
+	c.wclosed = true
+	c.mu.Unlock()
+	if send {
+		return c.send(true, []byte(noticeClose))
+	}
+	return nil
+}
+
+// Shutdown closes the writing side and waits for the peer to close its
+// side too, or for ctx to be done, before closing the stream. Data the
+// peer sends meanwhile is discarded.
+func (c *Conn) Shutdown(ctx context.Context) error {
+	if err := c.CloseWrite(); err != nil {
+		c.Close()
+		return err
+	}
+	c.mu.Lock()
+	for !c.eof && c.err == nil && !c.closed && ctx.Err() == nil {
+		c.buf, c.size = nil, 0
+		c.cond.Broadcast()
+		c.waitOr(ctx.Done())
+	}
+	c.mu.Unlock()
+	c.Close()
+	return ctx.Err()
+}
+
+// Close tells the peer that the stream ended and releases pending reads
+// and writes. The peer is not told when the transport still blocks on an
+// earlier message; ending the transport then tells it. Closing the stream
+// of a Session closes the session.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	s := c.session
+	send := c.open && !c.wclosed
+	c.closed = true
+	close(c.done)
+	c.buf, c.size = nil, 0
+	c.cond.Broadcast()
+	c.mu.Unlock()
+	if send {
+		select {
+		case c.sending <- struct{}{}:
+			c.tr.send(true, []byte(noticeClose))
+			<-c.sending
+		default:
+		}
+	}
+	if c.onClose != nil {
+		c.onClose()
+	}
+	if s != nil {
+		s.streamClosed(c)
+	}
+	return nil
+}
+
+// Session returns the session the stream was set up by, nil for a stream
+// of NewConn.
+func (c *Conn) Session() *Session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// LocalAddr implements net.Conn.
+func (c *Conn) LocalAddr() net.Addr { return c.local }
+
+// RemoteAddr implements net.Conn.
+func (c *Conn) RemoteAddr() net.Addr { return c.remote }
+
+// SetDeadline implements net.Conn.
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.SetReadDeadline(t)
+	return c.SetWriteDeadline(t)
+}
+
+// SetReadDeadline implements net.Conn.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.rd.set(t)
+	// Wake a pending Read to pick up the new deadline.
+	c.mu.Lock()
+	c.cond.Broadcast()
+	c.mu.Unlock()
+	return nil
+}
+
+// SetWriteDeadline implements net.Conn. It also fails the writes blocked
+// by the transport when it passes.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.wd.set(t)
+	return nil
+}
+
+// deadline is a settable point in time whose passing closes a channel.
+type deadline struct {
+	mu    sync.Mutex
+	timer *time.Timer
+	ch    chan struct{}
+}
+
+func (d *deadline) init() {
+	d.ch = make(chan struct{})
+}
+
+func (d *deadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil && !d.timer.Stop() {
+		<-d.ch // the timer fired and closed ch
+	}
+	d.timer = nil
+	select {
+	case <-d.ch:
+		d.ch = make(chan struct{})
+	default:
+	}
+	if t.IsZero() {
+		return
+	}
+	if dur := time.Until(t); dur > 0 {
+		ch := d.ch
+		d.timer = time.AfterFunc(dur, func() { close(ch) })
+		return
+	}
+	close(d.ch)
+}
+
+// wait returns a channel closed when the deadline passes.
+func (d *deadline) wait() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.ch
+}
+
+func (d *deadline) expired() bool {
+	select {
+	case <-d.wait():
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,256 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTransport records the messages sent, its sends waiting for gate to be
+// closed.
+type fakeTransport struct {
+	gate chan struct{} // closed to let sends through, nil to not wait
+
+	mu   sync.Mutex
+	sent []string // data, or notices prefixed with "!"
+}
+
+func (t *fakeTransport) send(notice bool, b []byte) error {
+	if t.gate != nil {
+		<-t.gate
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	msg := string(b)
+	if notice {
+		msg = "!" + msg
+	}
+	t.sent = append(t.sent, msg)
+	return nil
+}
+
+func (t *fakeTransport) messages() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.sent...)
+}
+
+// testConn returns an open stream over tr.
+func testConn(tr *fakeTransport) *Conn {
+	c := newConn(tr, Addr{Key: "key"}, Addr{Key: "key", Session: "session"})
+	c.mu.Lock()
+	c.open = true
+	c.mu.Unlock()
+	c.receive(true, []byte(noticeOpen))
+	return c
+}
+
+func TestOpenOrder(t *testing.T) {
+	// pions may deliver the peer's open before the channel's own.
+	tr := &fakeTransport{}
+	c := newConn(tr, Addr{Key: "key"}, Addr{Key: "key", Session: "session"})
+	notified := make(chan error, 1)
+	c.OnOpen(func() { notified <- c.Notify("hello") })
+	c.receive(true, []byte(noticeOpen))
+	if c.Ready() {
+		t.Fatal("ready before the channel opened")
+	}
+	c.opened()
+	select {
+	case err := <-notified:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnOpen not called once the channel opened")
+	}
+	if got := tr.messages(); len(got) != 2 || got[0] != "!"+noticeOpen || got[1] != "!hello" {
+		t.Errorf("sent %q, want the open and hello notices", got)
+	}
+}
+
+// returns runs f, failing the test unless it returns within a few seconds.
+func returns[T any](t *testing.T, f func() T) T {
+	t.Helper()
+	ch := make(chan T, 1)
+	go func() { ch <- f() }()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("still blocked")
+		panic("unreachable")
+	}
+}
+
+func TestWriteDeadline(t *testing.T) {
+	tr := &fakeTransport{gate: make(chan struct{})}
+	c := testConn(tr)
+	defer c.Close()
+
+	c.SetWriteDeadline(time.Now().Add(50 * time.Millisecond))
+	err := returns(t, func() error {
+		_, err := c.Write([]byte("blocked"))
+		return err
+	})
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("blocked Write: %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+	if _, err := c.Write([]byte("late")); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Write after the deadline: %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+
+	// A later write waits for the message given up on.
+	c.SetWriteDeadline(time.Time{})
+	written := make(chan error, 1)
+	go func() {
+		_, err := c.Write([]byte("next"))
+		written <- err
+	}()
+	close(tr.gate)
+	if err := returns(t, func() error { return <-written }); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tr.messages(), []string{"blocked", "next"}; !slices.Equal(got, want) {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestWriteDeadlineExtended(t *testing.T) {
+	tr := &fakeTransport{gate: make(chan struct{})}
+	c := testConn(tr)
+	defer c.Close()
+
+	c.SetWriteDeadline(time.Now().Add(time.Hour))
+	written := make(chan error, 1)
+	go func() {
+		_, err := c.Write([]byte("blocked"))
+		written <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	c.SetWriteDeadline(time.Now())
+	err := returns(t, func() error { return <-written })
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("blocked Write: %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+}
+
+func TestWriteClose(t *testing.T) {
+	tr := &fakeTransport{gate: make(chan struct{})}
+	c := testConn(tr)
+	written := make(chan error, 1)
+	go func() {
+		_, err := c.Write([]byte("blocked"))
+		written <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	returns(t, c.Close)
+	if err := returns(t, func() error { return <-written }); !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("blocked Write: %v, want %v", err, net.ErrClosed)
+	}
+	close(tr.gate)
+}
+
+func TestWriteSplit(t *testing.T) {
+	tr := &fakeTransport{}
+	c := testConn(tr)
+	defer c.Close()
+	b := make([]byte, 2*maxMessage+1)
+	if n, err := c.Write(b); n != len(b) || err != nil {
+		t.Fatalf("Write: %d, %v", n, err)
+	}
+	var sizes []int
+	for _, m := range tr.messages() {
+		sizes = append(sizes, len(m))
+	}
+	if len(sizes) != 3 || sizes[0] != maxMessage || sizes[1] != maxMessage || sizes[2] != 1 {
+		t.Errorf("message sizes %v", sizes)
+	}
+}
+
+func TestReadDeadline(t *testing.T) {
+	c := testConn(&fakeTransport{})
+	defer c.Close()
+	c.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	err := returns(t, func() error {
+		_, err := c.Read(make([]byte, 1))
+		return err
+	})
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Read: %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+
+	c.SetReadDeadline(time.Time{})
+	c.receive(false, []byte("data"))
+	b := make([]byte, 8)
+	if n, err := c.Read(b); err != nil || string(b[:n]) != "data" {
+		t.Fatalf("Read: %q, %v", b[:n], err)
+	}
+}
+
+func TestCloseWrite(t *testing.T) {
+	tr := &fakeTransport{}
+	c := testConn(tr)
+	defer c.Close()
+
+	if _, err := c.Write([]byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CloseWrite(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Write([]byte("more")); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Write after CloseWrite: %v, want %v", err, net.ErrClosed)
+	}
+
+	// Reads go on until the peer closes its side.
+	c.receive(false, []byte("reply"))
+	c.receive(true, []byte(noticeClose))
+	b, err := io.ReadAll(c)
+	if err != nil || string(b) != "reply" {
+		t.Errorf("read %q, %v; want reply", b, err)
+	}
+
+	c.Close()
+	if got, want := tr.messages(), []string{"data", "!" + noticeClose}; !slices.Equal(got, want) {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	tr := &fakeTransport{}
+	c := testConn(tr)
+	c.receive(false, []byte("discarded"))
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		c.receive(true, []byte(noticeClose))
+	}()
+	if err := returns(t, func() error { return c.Shutdown(context.Background()) }); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if _, err := c.Read(make([]byte, 1)); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Read after Shutdown: %v, want %v", err, net.ErrClosed)
+	}
+	if got, want := tr.messages(), []string{"!" + noticeClose}; !slices.Equal(got, want) {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	c := testConn(&fakeTransport{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := returns(t, func() error { return c.Shutdown(ctx) })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown: %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := c.Write([]byte("data")); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Write after Shutdown: %v, want %v", err, net.ErrClosed)
+	}
+}
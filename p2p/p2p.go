@@ -0,0 +1,443 @@
+// Package p2p opens streams between peers that find each other through a
+// signaling server. It runs the sessions of the ssh-p2p command, which
+// tunnels TCP connections over them.
+//
+// A listener pulls the offers sent to its key and a dialer pushes one, each
+// side then talking over a WebRTC DataChannel:
+//
+//	l, err := p2p.Listen(ctx, key, nil)
+//	...
+//	conn, err := l.Accept()
+//
+//	conn, err := p2p.Dial(ctx, key, nil)
+//
+// Peers that cannot connect directly fall back to a relay through the
+// signaling server when Options.RelayAfter is set. Both peers of a session
+// derive the same short authentication string, see Session.SAS, which
+// users compare to rule out someone in the middle.
+//
+// The streams carry no other authentication beyond knowledge of the key;
+// the ssh-p2p command adds its own steps to the handshake, such as short
+// codes and SSH keys, see Options.Handshake.
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nobonobo/ssh-p2p/signaling"
+	"github.com/pions/webrtc"
+)
+
+// DefaultConfiguration is used when Options has no ICE servers.
+var DefaultConfiguration = webrtc.RTCConfiguration{
+	IceServers: []webrtc.RTCIceServer{
+		{
+			URLs: []string{
+				"stun:stun.l.google.com:19302",
+			},
+		},
+	},
+}
+
+// DefaultOpenTimeout is used when Options has no OpenTimeout.
+const DefaultOpenTimeout = 30 * time.Second
+
+// Options configures Dial and Listen. The zero value uses the public
+// signaling server and STUN server.
+type Options struct {
+	// Signaling is the base URL of the signaling server, signaling.URI
+	// if empty.
+	Signaling string
+	// HTTPClient makes the requests to the signaling server,
+	// http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// Configuration is the PeerConnection configuration,
+	// DefaultConfiguration if it has no ICE servers.
+	Configuration webrtc.RTCConfiguration
+	// Logger receives the events of the streams, slog.Default() if nil.
+	Logger *slog.Logger
+	// OpenTimeout bounds the handshake of a session, from the offer until
+	// its stream is handed over, DefaultOpenTimeout if zero. A session
+	// that may fall back to the relay also gets RelayAfter and the wait
+	// for the relay.
+	OpenTimeout time.Duration
+	// RelayAfter, if positive, offers the peer the relay of the signaling
+	// server, which the session falls back to unless its DataChannel opens
+	// within that time. Both peers must offer it.
+	RelayAfter time.Duration
+	// Channel labels the DataChannel Dial opens, "data" if empty. A
+	// listener opens the one the offer names.
+	Channel string
+	// KeepCandidate, if set, tells the ICE candidates of the peer to
+	// connect to by their address.
+	KeepCandidate func(ip net.IP) bool
+	// Handshake, if set, returns the steps the application adds to the
+	// handshake of session s; an error rejects the session.
+	Handshake func(s *Session) (*Handshake, error)
+}
+
+func (o *Options) signaling() string {
+	if o == nil || o.Signaling == "" {
+		return signaling.URI
+	}
+	return o.Signaling
+}
+
+func (o *Options) httpClient() *http.Client {
+	if o == nil {
+		return nil
+	}
+	return o.HTTPClient
+}
+
+// client returns the client of the signaling server.
+func (o *Options) client() *signaling.Client {
+	return &signaling.Client{Base: o.signaling(), HTTPClient: o.httpClient()}
+}
+
+func (o *Options) configuration() webrtc.RTCConfiguration {
+	if o == nil || len(o.Configuration.IceServers) == 0 {
+		conf := DefaultConfiguration
+		if o != nil {
+			conf.Certificates = o.Configuration.Certificates
+		}
+		return conf
+	}
+	return o.Configuration
+}
+
+func (o *Options) openTimeout() time.Duration {
+	d := DefaultOpenTimeout
+	if o != nil && o.OpenTimeout > 0 {
+		d = o.OpenTimeout
+	}
+	if r := o.relayAfter(); r > 0 {
+		d += r + relayWait
+	}
+	return d
+}
+
+func (o *Options) relayAfter() time.Duration {
+	if o == nil {
+		return 0
+	}
+	return o.RelayAfter
+}
+
+func (o *Options) channel() string {
+	if o == nil || o.Channel == "" {
+		return defaultChannel
+	}
+	return o.Channel
+}
+
+// remoteSDP returns the peer's sdp without the candidates KeepCandidate
+// rejects.
+func (o *Options) remoteSDP(sdp string) string {
+	if o == nil || o.KeepCandidate == nil {
+		return sdp
+	}
+	return filterCandidates(sdp, o.KeepCandidate)
+}
+
+func (o *Options) logger() *slog.Logger {
+	if o == nil || o.Logger == nil {
+		return slog.Default()
+	}
+	return o.Logger
+}
+
+// Dial opens a stream to the listener of key. It returns once the
+// handshake handed the stream over, or failed, or ctx is done.
+func Dial(ctx context.Context, key string, opts *Options) (net.Conn, error) {
+	s := newSession(key, uuid.New().String(), true, opts)
+	s.channel = opts.channel()
+	if err := s.start(); err != nil {
+		return nil, err
+	}
+	c, err := s.dial(ctx)
+	if err != nil {
+		s.fail(err)
+		return nil, err
+	}
+	return c, nil
+}
+
+// dial pushes the offer of s and waits for its stream.
+func (s *Session) dial(ctx context.Context) (*Conn, error) {
+	nonce, err := newSASNonce()
+	if err != nil {
+		return nil, err
+	}
+	s.offerNonce = nonce
+	info := signaling.ConnectInfo{Source: s.id, SAS: sasCommitment(nonce)}
+	if s.channel != defaultChannel {
+		info.Channel = s.channel
+	}
+	if s.opts.relayAfter() > 0 {
+		if s.relay, err = NewRelay(); err != nil {
+			return nil, err
+		}
+		info.Relay = s.relay.PublicKey()
+	}
+	pc, err := webrtc.New(s.opts.configuration())
+	if err != nil {
+		return nil, err
+	}
+	dc, err := pc.CreateDataChannel(s.channel, nil)
+	if err != nil {
+		pc.Close()
+		return nil, err
+	}
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		pc.Close()
+		return nil, err
+	}
+	if err := s.setPeer(pc); err != nil {
+		pc.Close()
+		return nil, err
+	}
+	if err := s.setStream(NewConn(dc, Addr{Key: s.key, Session: s.id}, Addr{Key: s.key})); err != nil {
+		return nil, err
+	}
+	info.SDP = offer.Sdp
+	if s.hs.Offer != nil {
+		if err := s.hs.Offer(&info); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		for v := range s.opts.client().Pull(ctx, s.log, s.id) {
+			if err := s.answered(&info, &v); err != nil {
+				s.fail(err)
+			}
+			return
+		}
+	}()
+	if err := s.opts.client().Push(ctx, s.key, info); err != nil {
+		return nil, err
+	}
+	return s.wait(ctx)
+}
+
+// answered takes the answer v to the offer of s.
+func (s *Session) answered(offer, v *signaling.ConnectInfo) error {
+	if s.hs.Answer != nil {
+		if err := s.hs.Answer(offer, v); err != nil {
+			return err
+		}
+	}
+	if v.SAS == "" {
+		return errors.New("p2p: no sas nonce in answer")
+	}
+	s.mu.Lock()
+	s.answerNonce = v.SAS
+	s.mu.Unlock()
+	if s.relay != nil {
+		s.peerRelay = v.Relay
+	}
+	if err := s.peer().SetRemoteDescription(webrtc.RTCSessionDescription{
+		Type: webrtc.RTCSdpTypeAnswer,
+		Sdp:  s.opts.remoteSDP(v.SDP),
+	}); err != nil {
+		return err
+	}
+	s.startRelay(s.opts.remoteSDP(v.SDP))
+	return nil
+}
+
+// Answer answers the offer v pulled from the mailbox of a listener and
+// returns the stream once the handshake handed it over. It fails once ctx
+// is done first. The offer's Destination is the listener's key.
+func Answer(ctx context.Context, v signaling.ConnectInfo, opts *Options) (net.Conn, error) {
+	s := newSession(v.Destination, v.Source, false, opts)
+	s.channel = v.Channel
+	if s.channel == "" {
+		s.channel = defaultChannel
+	}
+	if err := s.start(); err != nil {
+		return nil, err
+	}
+	c, err := s.answer(ctx, v)
+	if err != nil {
+		s.fail(err)
+		return nil, err
+	}
+	return c, nil
+}
+
+// answer answers the offer v of s and waits for its stream.
+func (s *Session) answer(ctx context.Context, v signaling.ConnectInfo) (*Conn, error) {
+	if s.hs.Offer != nil {
+		if err := s.hs.Offer(&v); err != nil {
+			return nil, err
+		}
+	}
+	if v.SAS == "" {
+		return nil, errors.New("p2p: no sas commitment in offer")
+	}
+	nonce, err := newSASNonce()
+	if err != nil {
+		return nil, err
+	}
+	s.answerNonce = nonce
+	s.Expect(noticeSAS, func(args string) error {
+		if err := checkSASNonce(v.SAS, args); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.offerNonce = args
+		s.mu.Unlock()
+		return nil
+	})
+	info := signaling.ConnectInfo{Source: s.key, SAS: nonce}
+	if v.Relay != "" && s.opts.relayAfter() > 0 {
+		if s.relay, err = NewRelay(); err != nil {
+			return nil, err
+		}
+		s.peerRelay = v.Relay
+		info.Relay = s.relay.PublicKey()
+	}
+	pc, err := webrtc.New(s.opts.configuration())
+	if err != nil {
+		return nil, err
+	}
+	pc.OnDataChannel(func(dc *webrtc.RTCDataChannel) {
+		if dc.Label != s.channel {
+			s.fail(fmt.Errorf("p2p: data channel %q, want %q", dc.Label, s.channel))
+			return
+		}
+		st := NewConn(dc, Addr{Key: s.key}, Addr{Key: s.key, Session: s.id})
+		if err := s.setStream(st); err != nil {
+			st.Close()
+		}
+	})
+	remote := s.opts.remoteSDP(v.SDP)
+	if err := pc.SetRemoteDescription(webrtc.RTCSessionDescription{
+		Type: webrtc.RTCSdpTypeOffer,
+		Sdp:  remote,
+	}); err != nil {
+		pc.Close()
+		return nil, err
+	}
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		pc.Close()
+		return nil, err
+	}
+	// Set once the answer is made: pions does not guard Close against a
+	// concurrent CreateAnswer, and the stream cannot open before the push.
+	if err := s.setPeer(pc); err != nil {
+		pc.Close()
+		return nil, err
+	}
+	info.SDP = answer.Sdp
+	if s.hs.Answer != nil {
+		if err := s.hs.Answer(&v, &info); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.opts.client().Push(ctx, v.Source, info); err != nil {
+		return nil, err
+	}
+	s.startRelay(remote)
+	return s.wait(ctx)
+}
+
+// Listener accepts the streams dialed to a key.
+type Listener struct {
+	key    string
+	opts   *Options
+	ctx    context.Context
+	cancel context.CancelFunc
+	conns  chan *Conn
+	done   chan struct{}
+	once   sync.Once
+
+	mu      sync.Mutex
+	pending int // offers being answered
+}
+
+// Listen pulls the offers for key until ctx is done or the listener is
+// closed, answering each as Answer does.
+func Listen(ctx context.Context, key string, opts *Options) (net.Listener, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	l := &Listener{
+		key:    key,
+		opts:   opts,
+		ctx:    ctx,
+		cancel: cancel,
+		conns:  make(chan *Conn),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	go func() {
+		logger := opts.logger().With("key", key)
+		for v := range opts.client().Pull(ctx, logger, key) {
+			l.addPending(1)
+			go l.answer(v, logger)
+		}
+	}()
+	return l, nil
+}
+
+func (l *Listener) addPending(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending += n
+}
+
+func (l *Listener) answer(v signaling.ConnectInfo, logger *slog.Logger) {
+	v.Destination = l.key
+	c, err := Answer(l.ctx, v, l.opts)
+	l.addPending(-1)
+	if err != nil {
+		logger.Warn("answer failed", "session", v.Source, "err", err)
+		return
+	}
+	select {
+	case l.conns <- c.(*Conn):
+	case <-l.done:
+		c.Close()
+	}
+}
+
+// Accept waits for the next stream.
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops pulling offers and fails the handshakes still on. Streams
+// already accepted stay open.
+func (l *Listener) Close() error {
+	l.once.Do(func() {
+		close(l.done)
+		l.cancel()
+	})
+	return nil
+}
+
+// Addr returns the key of the listener.
+func (l *Listener) Addr() net.Addr {
+	return Addr{Key: l.key}
+}
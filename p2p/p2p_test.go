@@ -0,0 +1,374 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nobonobo/ssh-p2p/signaling"
+	"github.com/nobonobo/ssh-p2p/turn"
+	"github.com/pions/webrtc"
+	"github.com/pions/webrtc/pkg/ice"
+)
+
+const testTimeout = 30 * time.Second
+
+// testOptions returns options reaching a signaling server and a STUN server
+// on loopback, and a channel receiving the keys of the pulls made on the
+// signaling server.
+func testOptions(t *testing.T) (*Options, <-chan string) {
+	t.Helper()
+	pulls := make(chan string, 64)
+	srv := signaling.NewServer()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key, ok := strings.CutPrefix(r.URL.Path, "/pull/"); ok {
+			select {
+			case pulls <- key:
+			default:
+			}
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stun, err := turn.NewServer(conn, turn.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go stun.Serve()
+	t.Cleanup(func() { stun.Close() })
+
+	return &Options{
+		Signaling: ts.URL,
+		Configuration: webrtc.RTCConfiguration{
+			IceServers:       []webrtc.RTCIceServer{{URLs: []string{"stun:" + conn.LocalAddr().String()}}},
+			IceAgentSettings: ice.AgentSettings{NetworkTypes: []ice.NetworkType{ice.NetworkTypeUDP4}},
+		},
+	}, pulls
+}
+
+// listen listens on key and waits for the listener to pull its offers.
+func listen(t *testing.T, key string, opts *Options, pulls <-chan string) *Listener {
+	t.Helper()
+	l, err := Listen(context.Background(), key, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	for {
+		select {
+		case k := <-pulls:
+			if k == key {
+				return l.(*Listener)
+			}
+		case <-time.After(testTimeout):
+			t.Fatal("listener never pulled its offers")
+		}
+	}
+}
+
+// pushOffer pushes to key the offer of a PeerConnection that never reads
+// the answer, so that the stream of the listener never opens.
+func pushOffer(t *testing.T, key string, opts *Options) {
+	t.Helper()
+	pc, err := webrtc.New(opts.configuration())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	if _, err := pc.CreateDataChannel("data", nil); err != nil {
+		t.Fatal(err)
+	}
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := newSASNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := signaling.ConnectInfo{Source: uuid.New().String(), SDP: offer.Sdp, SAS: sasCommitment(nonce)}
+	if err := opts.client().Push(context.Background(), key, info); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// pendingCount returns the number of offers l is answering.
+func (l *Listener) pendingCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pending
+}
+
+// waitPending waits until l waits on n connections.
+func waitPending(t *testing.T, l *Listener, n int) {
+	t.Helper()
+	for end := time.Now().Add(testTimeout); l.pendingCount() != n; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(end) {
+			t.Fatalf("%d pending connections, want %d", l.pendingCount(), n)
+		}
+	}
+}
+
+// dialAccept dials the listener l of key with opts and returns both ends
+// of the stream.
+func dialAccept(t *testing.T, l *Listener, key string, opts *Options) (client, server *Conn) {
+	t.Helper()
+	dialed := make(chan net.Conn, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+		defer cancel()
+		c, err := Dial(ctx, key, opts)
+		if err != nil {
+			t.Error(err)
+		}
+		dialed <- c
+	}()
+	s, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	c := <-dialed
+	if c == nil {
+		t.FailNow()
+	}
+	t.Cleanup(func() { c.Close() })
+	return c.(*Conn), s.(*Conn)
+}
+
+func TestDialAccept(t *testing.T) {
+	opts, pulls := testOptions(t)
+	key := uuid.New().String()
+	l := listen(t, key, opts, pulls)
+	client, server := dialAccept(t, l, key, opts)
+	if n := l.pendingCount(); n != 0 {
+		t.Errorf("%d pending connections after Accept", n)
+	}
+
+	client.SetDeadline(time.Now().Add(testTimeout))
+	server.SetDeadline(time.Now().Add(testTimeout))
+	if _, err := client.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CloseWrite(); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := io.ReadAll(server); err != nil || string(b) != "ping" {
+		t.Fatalf("server read %q, %v; want ping", b, err)
+	}
+	if _, err := server.Write([]byte("pong")); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	if b, err := io.ReadAll(client); err != nil || string(b) != "pong" {
+		t.Fatalf("client read %q, %v; want pong", b, err)
+	}
+}
+
+func TestSAS(t *testing.T) {
+	opts, pulls := testOptions(t)
+	key := uuid.New().String()
+	l := listen(t, key, opts, pulls)
+	client, server := dialAccept(t, l, key, opts)
+	sas := client.Session().SAS()
+	if sas == "" {
+		t.Error("no SAS")
+	}
+	if got := server.Session().SAS(); got != sas {
+		t.Errorf("listener SAS %q, dialer SAS %q", got, sas)
+	}
+}
+
+func TestHandshakeProof(t *testing.T) {
+	opts, pulls := testOptions(t)
+	lopts := *opts
+	lopts.Handshake = func(s *Session) (*Handshake, error) {
+		s.Expect("token", func(args string) error {
+			if args != "secret" {
+				return errors.New("wrong token")
+			}
+			return nil
+		})
+		return nil, nil
+	}
+	key := uuid.New().String()
+	l := listen(t, key, &lopts, pulls)
+	dialer := func(token string) *Options {
+		dopts := *opts
+		dopts.Handshake = func(s *Session) (*Handshake, error) {
+			return &Handshake{Open: func() error { return s.Notify("token " + token) }}, nil
+		}
+		return &dopts
+	}
+
+	client, server := dialAccept(t, l, key, dialer("secret"))
+	if _, err := client.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	server.SetReadDeadline(time.Now().Add(testTimeout))
+	if _, err := io.ReadFull(server, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("server read %q, %v; want ping", buf, err)
+	}
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if c, err := l.Accept(); err == nil {
+			accepted <- c
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	c, err := Dial(ctx, key, dialer("guess"))
+	if err == nil {
+		// The dialer owes no proof, so its stream is handed over before
+		// the listener rejects it.
+		defer c.Close()
+		c.SetReadDeadline(time.Now().Add(testTimeout))
+		if _, err := c.Read(buf); err == nil {
+			t.Error("read from a stream the listener rejected")
+		}
+	}
+	select {
+	case c := <-accepted:
+		c.Close()
+		t.Error("accepted a stream with a wrong proof")
+	case <-time.After(time.Second):
+	}
+}
+
+func TestRelayFallback(t *testing.T) {
+	opts, pulls := testOptions(t)
+	opts.RelayAfter = time.Second
+	// Without the peer's candidates ICE cannot connect, so both sides
+	// fall back to the relay right away.
+	opts.KeepCandidate = func(net.IP) bool { return false }
+	key := uuid.New().String()
+	l := listen(t, key, opts, pulls)
+	client, server := dialAccept(t, l, key, opts)
+	for _, c := range []*Conn{client, server} {
+		if pair := c.Session().PairType(); pair != "relay" {
+			t.Errorf("pair %q, want relay", pair)
+		}
+	}
+	if client.Session().SAS() != server.Session().SAS() {
+		t.Error("peers show different SAS over the relay")
+	}
+	if _, err := client.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	client.CloseWrite()
+	server.SetReadDeadline(time.Now().Add(testTimeout))
+	if b, err := io.ReadAll(server); err != nil || string(b) != "ping" {
+		t.Fatalf("server read %q, %v; want ping", b, err)
+	}
+}
+
+func TestListenerClose(t *testing.T) {
+	opts, pulls := testOptions(t)
+	key := uuid.New().String()
+	l := listen(t, key, opts, pulls)
+	accepted := make(chan error, 1)
+	go func() {
+		_, err := l.Accept()
+		accepted <- err
+	}()
+	l.Close()
+	select {
+	case err := <-accepted:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("Accept: %v, want %v", err, net.ErrClosed)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("Accept still waiting after Close")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if c, err := Dial(ctx, key, opts); err == nil {
+		c.Close()
+		t.Error("dialed a closed listener")
+	}
+}
+
+func TestOpenTimeout(t *testing.T) {
+	opts, pulls := testOptions(t)
+	opts.OpenTimeout = time.Second
+	key := uuid.New().String()
+	l := listen(t, key, opts, pulls)
+	pushOffer(t, key, opts)
+	waitPending(t, l, 1)
+	waitPending(t, l, 0)
+}
+
+func TestCloseClosesPending(t *testing.T) {
+	opts, pulls := testOptions(t)
+	key := uuid.New().String()
+	l := listen(t, key, opts, pulls)
+	pushOffer(t, key, opts)
+	waitPending(t, l, 1)
+	l.Close()
+	waitPending(t, l, 0)
+}
+
+func TestCloseHandshaking(t *testing.T) {
+	opts, _ := testOptions(t)
+	// Closing both ends once ICE connects fails their DTLS or SCTP
+	// handshake, which must neither crash nor keep Close waiting.
+	for i := 0; i < 5; i++ {
+		offer, err := webrtc.New(opts.configuration())
+		if err != nil {
+			t.Fatal(err)
+		}
+		answer, err := webrtc.New(opts.configuration())
+		if err != nil {
+			t.Fatal(err)
+		}
+		closed := make(chan error, 2)
+		for _, pc := range []*webrtc.RTCPeerConnection{offer, answer} {
+			var once sync.Once
+			pc.OnICEConnectionStateChange(func(state ice.ConnectionState) {
+				if state == ice.ConnectionStateConnected {
+					once.Do(func() { go func() { closed <- pc.Close() }() })
+				}
+			})
+		}
+		if _, err := offer.CreateDataChannel("data", nil); err != nil {
+			t.Fatal(err)
+		}
+		o, err := offer.CreateOffer(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := answer.SetRemoteDescription(o); err != nil {
+			t.Fatal(err)
+		}
+		a, err := answer.CreateAnswer(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := offer.SetRemoteDescription(a); err != nil {
+			t.Fatal(err)
+		}
+		for range 2 {
+			select {
+			case <-closed:
+			case <-time.After(testTimeout):
+				t.Fatal("Close still waiting")
+			}
+		}
+	}
+}
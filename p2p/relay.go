@@ -0,0 +1,240 @@
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nobonobo/ssh-p2p/signaling"
+)
+
+// A relayed stream runs through the relay endpoint of the signaling server,
+// which pairs the two WebSockets opened for a session and copies what each
+// sends to the other. The peers exchange ephemeral X25519 keys along with
+// the offer and answer and seal every message with keys derived from them,
+// so the relay only sees ciphertext. Since the keys' fingerprints stand in
+// for the DTLS ones, whatever authenticates a session authenticates its
+// relayed stream as well. A side closing the stream seals an end message
+// last, so that the relay cannot pass off cutting the stream as its end.
+
+const (
+	// maxFrame bounds the sealed messages accepted from the relay.
+	maxFrame = 1 << 16
+	// relayLinger bounds the wait of a closed relayed stream for its end
+	// message to go out.
+	relayLinger = 5 * time.Second
+)
+
+// Kinds of sealed messages, told by their first byte.
+const (
+	relayData byte = iota
+	relayNotice
+	relayEnd // the sender closed the stream
+)
+
+var errRelayCut = errors.New("p2p: relay ended the stream before the peer")
+
+// Relay holds one side's key for a relayed stream.
+type Relay struct {
+	// HTTPClient opens the WebSocket to the relay, http.DefaultClient if
+	// nil.
+	HTTPClient *http.Client
+
+	priv *ecdh.PrivateKey
+}
+
+// NewRelay returns a side with a new key.
+func NewRelay() (*Relay, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Relay{priv: priv}, nil
+}
+
+// PublicKey returns the public key to send to the peer.
+func (r *Relay) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(r.priv.PublicKey().Bytes())
+}
+
+// RelayFingerprint returns the fingerprint of a relay public key in the
+// format of an SDP DTLS fingerprint, or "" if pub is malformed.
+func RelayFingerprint(pub string) string {
+	b, err := base64.RawURLEncoding.DecodeString(pub)
+	if err != nil || len(b) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	hex := make([]string, len(sum))
+	for i, v := range sum {
+		hex[i] = fmt.Sprintf("%02X", v)
+	}
+	return "SHA-256 " + strings.Join(hex, ":")
+}
+
+// Dial opens the relayed stream of session on the signaling server at base
+// to the peer with public key peer; offer tells whether this side made
+// the offer. It returns once the relay paired both sides or ctx is done.
+// As with NewConn the stream opens once the peer's side did; see OnOpen.
+func (r *Relay) Dial(ctx context.Context, base, session string, offer bool, peer string, local, remote net.Addr) (*Conn, error) {
+	b, err := base64.RawURLEncoding.DecodeString(peer)
+	if err != nil {
+		return nil, fmt.Errorf("p2p: relay key: %v", err)
+	}
+	pub, err := ecdh.X25519().NewPublicKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("p2p: relay key: %v", err)
+	}
+	secret, err := r.priv.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	offerKey, answerKey := r.PublicKey(), peer
+	if !offer {
+		offerKey, answerKey = peer, offerKey
+	}
+	keys, err := hkdf.Key(sha256.New, secret, nil, "ssh-p2p relay\n"+offerKey+"\n"+answerKey, 64)
+	if err != nil {
+		return nil, err
+	}
+	sealKey, openKey := keys[:32], keys[32:]
+	if !offer {
+		sealKey, openKey = openKey, sealKey
+	}
+	t := &relay{}
+	if t.seal, err = newAEAD(sealKey); err != nil {
+		return nil, err
+	}
+	if t.open, err = newAEAD(openKey); err != nil {
+		return nil, err
+	}
+
+	// The relay answers the handshake once the peer's side arrived, so ctx
+	// bounds the wait for the peer; the exchange outlives it.
+	t.ws, err = signaling.DialWebSocket(ctx, r.HTTPClient, base+path.Join("/", "relay", session))
+	if err != nil {
+		return nil, fmt.Errorf("p2p: relay: %v", err)
+	}
+	c := newConn(t, local, remote)
+	c.onClose = func() {
+		go func() {
+			// Closing the WebSocket fails a write blocking the end.
+			linger := time.AfterFunc(relayLinger, func() { t.ws.Close() })
+			defer linger.Stop()
+			t.write(relayEnd, nil)
+			// Ending our side ends the exchange for both.
+			t.ws.Close()
+		}()
+	}
+	go t.run(c)
+	c.opened()
+	return c, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// relay is the transport of a relayed stream. Each message is sent as a
+// big-endian length followed by the sealed message, whose first byte tells
+// its kind. The nonces count the messages of each direction.
+type relay struct {
+	seal, open cipher.AEAD
+	ws         io.ReadWriteCloser
+
+	mu   sync.Mutex
+	sent uint64
+}
+
+func nonce(aead cipher.AEAD, n uint64) []byte {
+	b := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(b[len(b)-8:], n)
+	return b
+}
+
+func (t *relay) send(notice bool, b []byte) error {
+	if notice {
+		return t.write(relayNotice, b)
+	}
+	return t.write(relayData, b)
+}
+
+// write seals and sends a message of the given kind.
+func (t *relay) write(kind byte, b []byte) error {
+	plain := append([]byte{kind}, b...)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sealed := t.seal.Seal(nil, nonce(t.seal, t.sent), plain, nil)
+	t.sent++
+	frame := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(sealed)), uint32(len(sealed)))
+	_, err := t.ws.Write(append(frame, sealed...))
+	return err
+}
+
+// run passes the messages read from the relay to c until the peer's end
+// message, which ends the stream as if the peer closed it. The exchange
+// ending before it fails reads once the data received is read, and a
+// message failing to open closes the stream.
+func (t *relay) run(c *Conn) {
+	ended := false
+	defer func() {
+		// Fail writes blocked on an exchange that is over.
+		t.ws.Close()
+		if ended {
+			c.receive(true, []byte(noticeClose))
+		} else {
+			c.fail(errRelayCut)
+		}
+	}()
+	br := bufio.NewReader(t.ws)
+	var hdr [4]byte
+	for n := uint64(0); ; n++ {
+		if _, err := io.ReadFull(br, hdr[:]); err != nil {
+			return
+		}
+		size := binary.BigEndian.Uint32(hdr[:])
+		if size > maxFrame {
+			c.Close()
+			return
+		}
+		sealed := make([]byte, size)
+		if _, err := io.ReadFull(br, sealed); err != nil {
+			return
+		}
+		plain, err := t.open.Open(sealed[:0], nonce(t.open, n), sealed, nil)
+		if err != nil || len(plain) == 0 {
+			c.Close()
+			return
+		}
+		switch plain[0] {
+		case relayData, relayNotice:
+			c.receive(plain[0] == relayNotice, plain[1:])
+		case relayEnd:
+			ended = true
+			return
+		default:
+			c.Close()
+			return
+		}
+	}
+}
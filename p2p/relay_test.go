@@ -0,0 +1,99 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/nobonobo/ssh-p2p/signaling"
+)
+
+// relayPair returns both sides of a relayed stream through a signaling
+// server at base, the offering one making its requests with client.
+func relayPair(t *testing.T, base string, client *http.Client) (offer, answer *Conn) {
+	t.Helper()
+	ro, err := NewRelay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ra, err := NewRelay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ro.HTTPClient = client
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	answered := make(chan *Conn, 1)
+	go func() {
+		c, err := ra.Dial(ctx, base, "session", false, ro.PublicKey(), Addr{Key: "key"}, Addr{Key: "key", Session: "session"})
+		if err != nil {
+			t.Error(err)
+		}
+		answered <- c
+	}()
+	offer, err = ro.Dial(ctx, base, "session", true, ra.PublicKey(), Addr{Key: "key", Session: "session"}, Addr{Key: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { offer.Close() })
+	if answer = <-answered; answer == nil {
+		t.FailNow()
+	}
+	t.Cleanup(func() { answer.Close() })
+	return offer, answer
+}
+
+func TestRelay(t *testing.T) {
+	ts := httptest.NewServer(signaling.NewServer())
+	defer ts.Close()
+	offer, answer := relayPair(t, ts.URL, nil)
+
+	if _, err := offer.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	offer.Close()
+	if b, err := io.ReadAll(answer); err != nil || string(b) != "ping" {
+		t.Fatalf("read %q, %v; want ping and the end", b, err)
+	}
+}
+
+func TestRelayCut(t *testing.T) {
+	ts := httptest.NewServer(signaling.NewServer())
+	defer ts.Close()
+	var mu sync.Mutex
+	var conns []net.Conn
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		c, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+		if err == nil {
+			mu.Lock()
+			conns = append(conns, c)
+			mu.Unlock()
+		}
+		return c, err
+	}
+	offer, answer := relayPair(t, ts.URL, &http.Client{Transport: tr})
+
+	if _, err := offer.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(answer, buf); err != nil {
+		t.Fatal(err)
+	}
+	// The relay dropping the offering side ends the exchange without its
+	// end message.
+	mu.Lock()
+	for _, c := range conns {
+		c.Close()
+	}
+	mu.Unlock()
+	if b, err := io.ReadAll(answer); !errors.Is(err, errRelayCut) {
+		t.Fatalf("read %q, %v; want %v", b, err, errRelayCut)
+	}
+}
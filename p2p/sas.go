@@ -0,0 +1,56 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// sasNonceSize is the size of the nonces the short authentication string
+// is derived from.
+const sasNonceSize = 32
+
+// newSASNonce returns a random nonce to derive the short authentication
+// string from.
+func newSASNonce() (string, error) {
+	b := make([]byte, sasNonceSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// sasCommitment returns the commitment to the dialer's nonce that the
+// offer carries.
+func sasCommitment(nonce string) string {
+	sum := sha256.Sum256([]byte("ssh-p2p sas commitment\n" + nonce))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// checkSASNonce verifies the nonce the dialer revealed against the
+// commitment of its offer.
+func checkSASNonce(commitment, nonce string) error {
+	if b, err := base64.RawURLEncoding.DecodeString(nonce); err != nil || len(b) != sasNonceSize {
+		return errors.New("p2p: malformed sas nonce")
+	}
+	if subtle.ConstantTimeCompare([]byte(sasCommitment(nonce)), []byte(commitment)) != 1 {
+		return errors.New("p2p: sas nonce does not match the commitment")
+	}
+	return nil
+}
+
+// shortAuthString returns six digits derived from the offer and answer
+// fingerprints and from a nonce of each peer. The dialer commits to its
+// nonce in the offer and reveals it only after the answer brought the
+// listener's, so a peer in the middle has to pick its own nonces and keys
+// before learning the genuine nonces and cannot search for ones making
+// both sides show the same string: it goes unnoticed once in a million.
+func shortAuthString(offerFP, answerFP, offerNonce, answerNonce string) string {
+	sum := sha256.Sum256([]byte("ssh-p2p sas\n" + offerFP + "\n" + answerFP + "\n" + offerNonce + "\n" + answerNonce))
+	n := binary.BigEndian.Uint32(sum[:4]) % 1000000
+	return fmt.Sprintf("%03d %03d", n/1000, n%1000)
+}
@@ -0,0 +1,43 @@
+package p2p
+
+import "testing"
+
+func TestSASCommitment(t *testing.T) {
+	nonce, err := newSASNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment := sasCommitment(nonce)
+	if err := checkSASNonce(commitment, nonce); err != nil {
+		t.Fatalf("check of the committed nonce: %v", err)
+	}
+	other, err := newSASNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSASNonce(commitment, other); err == nil {
+		t.Error("check of another nonce passed")
+	}
+	if err := checkSASNonce(sasCommitment("short"), "short"); err == nil {
+		t.Error("check of a malformed nonce passed")
+	}
+}
+
+func TestShortAuthString(t *testing.T) {
+	sas := shortAuthString("offer", "answer", "n1", "n2")
+	if len(sas) != 7 || sas[3] != ' ' {
+		t.Fatalf("sas %q, want two groups of three digits", sas)
+	}
+	if sas != shortAuthString("offer", "answer", "n1", "n2") {
+		t.Error("sas is not deterministic")
+	}
+	for _, other := range []string{
+		shortAuthString("offer", "answer", "n1", "n3"),
+		shortAuthString("offer", "answer", "n3", "n2"),
+		shortAuthString("offer", "other", "n1", "n2"),
+	} {
+		if other == sas {
+			t.Errorf("sas %q does not depend on its inputs", sas)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package p2p
+
+import (
+	"net"
+	"strings"
+)
+
+// SDPFingerprint returns the value of the DTLS fingerprint attribute of
+// sdp, in the format of RelayFingerprint.
+func SDPFingerprint(sdp string) string {
+	for _, line := range strings.Split(sdp, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "a=fingerprint:") {
+			return strings.ToUpper(strings.TrimPrefix(line, "a=fingerprint:"))
+		}
+	}
+	return ""
+}
+
+// CandidateIPs returns the addresses of the ICE candidates listed in sdp.
+func CandidateIPs(sdp string) []net.IP {
+	var ips []net.IP
+	for _, line := range strings.Split(sdp, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "a=candidate:") {
+			continue
+		}
+		if ip := candidateIP(line); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// candidateIP returns the address of the candidate on an a=candidate line.
+func candidateIP(line string) net.IP {
+	fields := strings.Fields(line)
+	if len(fields) < 6 {
+		return nil
+	}
+	return net.ParseIP(fields[4])
+}
+
+// filterCandidates removes the ICE candidates of sdp that keep rejects.
+func filterCandidates(sdp string, keep func(ip net.IP) bool) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(sdp, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "a=candidate:") {
+			if ip := candidateIP(line); ip == nil || !keep(ip) {
+				continue
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
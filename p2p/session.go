@@ -0,0 +1,530 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nobonobo/ssh-p2p/signaling"
+	"github.com/pions/webrtc"
+	"github.com/pions/webrtc/pkg/ice"
+)
+
+// The handshake of a session runs over the signaling server and then over
+// the session's stream. The dialer's offer carries a commitment to its
+// nonce of the short authentication string and the listener's answer its
+// own nonce; once the stream opened, the dialer reveals its nonce in a sas
+// notice. Before the stream is handed over, the peer must give every proof
+// the session expects, see Session.Expect, within proofTimeout. A session
+// whose peers both offered the relay falls back to it unless its
+// DataChannel opens within Options.RelayAfter.
+
+// noticeSAS carries the dialer's nonce of the short authentication string.
+const noticeSAS = "sas"
+
+const (
+	// proofTimeout bounds the wait for the peer's proofs once the stream
+	// opened.
+	proofTimeout = 10 * time.Second
+	// relayWait bounds the wait for the peer at the relay.
+	relayWait = 30 * time.Second
+	// defaultChannel labels the DataChannel of an offer naming none.
+	defaultChannel = "data"
+)
+
+var (
+	errRelayed = errors.New("p2p: session is relayed")
+	errNoProof = errors.New("p2p: no proof from peer")
+)
+
+// Handshake holds the steps an application adds to the handshake of a
+// session, see Options.Handshake. Any may be nil; an error fails the
+// session.
+type Handshake struct {
+	// Offer is called by Dial on the offer before it is pushed, and by
+	// Answer on the offer before a PeerConnection is made for it.
+	Offer func(offer *signaling.ConnectInfo) error
+	// Answer is called by Answer on the answer before it is pushed, and by
+	// Dial on the answer pulled.
+	Answer func(offer, answer *signaling.ConnectInfo) error
+	// Open is called once the stream opened, before the proofs the peer
+	// owes are waited for, to send those of this side.
+	Open func() error
+}
+
+// proof is a notice the peer must send before the stream is handed over.
+type proof struct {
+	verify func(args string) error
+	done   chan struct{} // closed once verified
+}
+
+// Session is the setup of a stream between two peers and what the stream
+// runs over: a PeerConnection, and the relay once it falls back to it. The
+// steps of a Handshake act on it, and it stays reachable from the stream,
+// see Conn.Session.
+type Session struct {
+	key     string
+	id      string // the dialer's mailbox
+	offer   bool   // this side dialed
+	channel string
+	opts    *Options
+	log     *slog.Logger
+	hs      *Handshake
+
+	relay     *Relay // nil unless this side offered the relay
+	peerRelay string // the peer's relay key, if it offered one
+
+	mu          sync.Mutex
+	pc          *webrtc.RTCPeerConnection
+	stream      *Conn
+	proofs      map[string]*proof // by notice
+	onNotice    func(string)
+	offerNonce  string
+	answerNonce string
+	relayed     bool
+	opened      bool  // the handshake on the stream started
+	over        bool  // the handshake is over
+	err         error // what failed the handshake
+	closed      bool
+	ready       chan struct{} // closed once the stream is handed over
+	done        chan struct{} // closed by Close
+}
+
+func newSession(key, id string, offer bool, opts *Options) *Session {
+	return &Session{
+		key:   key,
+		id:    id,
+		offer: offer,
+		opts:  opts,
+		log:   opts.logger().With("key", key, "session", id),
+		hs:    &Handshake{},
+		ready: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// start runs the Handshake hook of the options on s.
+func (s *Session) start() error {
+	if s.opts == nil || s.opts.Handshake == nil {
+		return nil
+	}
+	hs, err := s.opts.Handshake(s)
+	if err != nil {
+		return err
+	}
+	if hs != nil {
+		s.hs = hs
+	}
+	return nil
+}
+
+// Key returns the key of the listener.
+func (s *Session) Key() string { return s.key }
+
+// ID returns the id of the session, the dialer's mailbox.
+func (s *Session) ID() string { return s.id }
+
+// Channel returns the label of the session's DataChannel.
+func (s *Session) Channel() string { return s.channel }
+
+// Logger returns the logger of the session.
+func (s *Session) Logger() *slog.Logger { return s.log }
+
+// setPeer sets the PeerConnection of the session, unless it was closed.
+func (s *Session) setPeer(pc *webrtc.RTCPeerConnection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return net.ErrClosed
+	}
+	s.pc = pc
+	pc.OnICEConnectionStateChange(s.iceStateChanged)
+	return nil
+}
+
+func (s *Session) peer() *webrtc.RTCPeerConnection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pc
+}
+
+// setStream sets the stream the handshake goes on over.
+func (s *Session) setStream(st *Conn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.closed:
+		return net.ErrClosed
+	case s.relayed && !isRelay(st.tr):
+		return errRelayed
+	}
+	s.stream = st
+	st.mu.Lock()
+	st.session = s
+	st.mu.Unlock()
+	st.OnNotice(s.handleNotice)
+	st.OnOpen(s.handshake)
+	return nil
+}
+
+func isRelay(tr transport) bool {
+	_, ok := tr.(*relay)
+	return ok
+}
+
+// streamClosed closes the session if st is still its stream.
+func (s *Session) streamClosed(st *Conn) {
+	s.mu.Lock()
+	current := s.stream == st
+	s.mu.Unlock()
+	if current {
+		s.Close()
+	}
+}
+
+func (s *Session) isRelayed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.relayed
+}
+
+// Fingerprints returns the fingerprints the proofs of the session bind to:
+// those of the DTLS certificates, or of the relay keys once relayed.
+func (s *Session) Fingerprints() (offerFP, answerFP string) {
+	if s.isRelayed() {
+		offerFP, answerFP = RelayFingerprint(s.relay.PublicKey()), RelayFingerprint(s.peerRelay)
+	} else {
+		pc := s.peer()
+		offerFP, answerFP = SDPFingerprint(pc.LocalDescription().Sdp), SDPFingerprint(pc.RemoteDescription().Sdp)
+	}
+	if !s.offer {
+		offerFP, answerFP = answerFP, offerFP
+	}
+	return offerFP, answerFP
+}
+
+// SAS returns the short authentication string of the session, six digits
+// both peers show alike unless someone is in the middle. It is only known
+// once the stream opened.
+func (s *Session) SAS() string {
+	offerFP, answerFP := s.Fingerprints()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return shortAuthString(offerFP, answerFP, s.offerNonce, s.answerNonce)
+}
+
+// PairType describes the path of the stream: "relay" through the signaling
+// server, otherwise the types of the local and remote candidates of the
+// pair ICE selected, such as "host/srflx" or "relay/host" through a TURN
+// server.
+func (s *Session) PairType() string {
+	if s.isRelayed() {
+		return "relay"
+	}
+	local, remote, err := s.peer().SelectedCandidatePair()
+	if err != nil || local == nil {
+		return "none"
+	}
+	return local.Type.String() + "/" + remote.Type.String()
+}
+
+// PeerIP returns the address the peer's side of the selected ICE pair
+// sends from, or nil when it is not the peer's own: relayed over the
+// signaling server or through a TURN server of the peer.
+func (s *Session) PeerIP() net.IP {
+	if s.isRelayed() {
+		return nil
+	}
+	_, remote, err := s.peer().SelectedCandidatePair()
+	if err != nil || remote == nil || remote.Type == ice.CandidateTypeRelay {
+		return nil
+	}
+	return remote.IP
+}
+
+// Transport names what the stream runs over from this side: "udp" for
+// ICE, unless the selected pair relays through a TURN server over "tcp" or
+// "tls", and for the relay "tcp" or "tls" to the signaling server.
+func (s *Session) Transport() string {
+	if !s.isRelayed() {
+		local, _, err := s.peer().SelectedCandidatePair()
+		if err == nil && local != nil && local.RelayProtocol != "" {
+			return local.RelayProtocol
+		}
+		return "udp"
+	}
+	if strings.HasPrefix(s.opts.signaling(), "https://") {
+		return "tls"
+	}
+	return "tcp"
+}
+
+// relayAfter falls back to the relay unless the session's stream opened
+// within d. Both peers must have offered the relay.
+func (s *Session) relayAfter(d time.Duration) {
+	if d <= 0 || s.relay == nil || s.peerRelay == "" {
+		return
+	}
+	time.AfterFunc(d, func() {
+		s.mu.Lock()
+		st, closed := s.stream, s.closed
+		s.mu.Unlock()
+		if closed || st != nil && st.Ready() {
+			return
+		}
+		s.fallback()
+	})
+}
+
+// startRelay arranges the fallback to the relay: right away when the
+// peer's sdp, less the candidates Options.KeepCandidate rejects, rules out
+// ICE, and after Options.RelayAfter otherwise.
+func (s *Session) startRelay(peerSDP string) {
+	if s.relay == nil || s.peerRelay == "" {
+		return
+	}
+	if len(CandidateIPs(peerSDP)) > 0 {
+		s.relayAfter(s.opts.relayAfter())
+		return
+	}
+	go s.fallback()
+}
+
+// fallback moves the session from its DataChannel to the relay.
+func (s *Session) fallback() {
+	s.mu.Lock()
+	old := s.stream
+	s.stream = nil
+	s.relayed = true
+	s.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	s.log.Warn("no direct connection, falling back to the relay", "transport", s.Transport())
+	ctx, cancel := context.WithTimeout(context.Background(), relayWait)
+	defer cancel()
+	local, remote := Addr{Key: s.key, Session: s.id}, Addr{Key: s.key}
+	if !s.offer {
+		local, remote = remote, local
+	}
+	s.relay.HTTPClient = s.opts.httpClient()
+	st, err := s.relay.Dial(ctx, s.opts.signaling(), s.id, s.offer, s.peerRelay, local, remote)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	if err := s.setStream(st); err != nil {
+		st.Close()
+	}
+}
+
+// Expect makes the session require a notice accepted by verify before its
+// stream is handed over. It must be called before the stream opens.
+func (s *Session) Expect(notice string, verify func(args string) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.proofs == nil {
+		s.proofs = map[string]*proof{}
+	}
+	s.proofs[notice] = &proof{verify: verify, done: make(chan struct{})}
+}
+
+func (s *Session) pendingProofs() []*proof {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ps []*proof
+	for _, p := range s.proofs {
+		select {
+		case <-p.done:
+		default:
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// waitProof waits for the peer to give every proof due.
+func (s *Session) waitProof(timeout time.Duration) error {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	for _, p := range s.pendingProofs() {
+		select {
+		case <-p.done:
+		case <-s.done:
+			return net.ErrClosed
+		case <-t.C:
+			return errNoProof
+		}
+	}
+	return nil
+}
+
+// checkProof verifies a proof notice from the peer.
+func (s *Session) checkProof(notice string, p *proof, args string) {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	if err := p.verify(args); err != nil {
+		s.log.Error("proof failed", "notice", notice, "err", err)
+		s.fail(err)
+		return
+	}
+	s.log.Debug("proof verified", "notice", notice)
+	close(p.done)
+}
+
+// OnNotice sets the handler of the peer's notices that are not proofs the
+// session expects.
+func (s *Session) OnNotice(f func(msg string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onNotice = f
+}
+
+// Notify sends a notice to the peer if the stream is up.
+func (s *Session) Notify(msg string) error {
+	s.mu.Lock()
+	st := s.stream
+	s.mu.Unlock()
+	if st == nil {
+		return nil
+	}
+	return st.Notify(msg)
+}
+
+// handleNotice passes a notice of the peer to the proof it gives or to the
+// handler set by OnNotice. Proofs that are not due are ignored, since the
+// peer cannot tell which ones are.
+func (s *Session) handleNotice(msg string) {
+	name, args, _ := strings.Cut(msg, " ")
+	s.mu.Lock()
+	p, f := s.proofs[name], s.onNotice
+	s.mu.Unlock()
+	switch {
+	case p != nil:
+		s.checkProof(name, p, args)
+	case name == noticeSAS:
+		s.log.Debug("proof not required", "notice", name)
+	case f != nil:
+		f(msg)
+	default:
+		s.log.Debug("notice ignored", "notice", name)
+	}
+}
+
+// handshake runs the handshake on the stream once it opened: the dialer
+// reveals its nonce, the Open step sends the proofs of this side and those
+// of the peer are waited for.
+func (s *Session) handshake() {
+	s.mu.Lock()
+	if s.opened || s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.opened = true
+	nonce := s.offerNonce
+	s.mu.Unlock()
+	if s.offer {
+		s.Notify(noticeSAS + " " + nonce)
+	}
+	if s.hs.Open != nil {
+		if err := s.hs.Open(); err != nil {
+			s.fail(err)
+			return
+		}
+	}
+	if err := s.waitProof(proofTimeout); err != nil {
+		s.fail(err)
+		return
+	}
+	if s.finish(nil) {
+		close(s.ready)
+	}
+}
+
+// finish ends the handshake with err, reporting whether it was still on.
+func (s *Session) finish(err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.over {
+		return false
+	}
+	s.over, s.err = true, err
+	return true
+}
+
+// fail ends the handshake with err and closes the session.
+func (s *Session) fail(err error) {
+	if s.finish(err) {
+		s.log.Warn("handshake failed", "err", err)
+	}
+	s.Close()
+}
+
+// wait waits for the handshake to hand the stream over, failing it when
+// ctx is done or the open timeout passed first.
+func (s *Session) wait(ctx context.Context) (*Conn, error) {
+	t := time.NewTimer(s.opts.openTimeout())
+	defer t.Stop()
+	select {
+	case <-s.ready:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.stream, nil
+	case <-s.done:
+	case <-ctx.Done():
+		s.fail(ctx.Err())
+	case <-t.C:
+		s.log.Warn("stream not opened in time", "timeout", s.opts.openTimeout())
+		s.fail(errOpenTimeout)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return nil, s.err
+}
+
+var errOpenTimeout = errors.New("p2p: stream not opened in time")
+
+// iceStateChanged logs the ICE state of the session and closes it once
+// ICE is lost, unless it is relayed or may still fall back to the relay.
+func (s *Session) iceStateChanged(state ice.ConnectionState) {
+	s.log.Info("ice state changed", "state", state.String())
+	switch state {
+	case ice.ConnectionStateDisconnected:
+		if !s.isRelayed() {
+			s.Close()
+		}
+	case ice.ConnectionStateFailed:
+		if s.relay == nil || s.peerRelay == "" {
+			s.Close()
+		}
+	}
+}
+
+// Close ends the session: its stream, the relayed one or the DataChannel,
+// and its PeerConnection. A handshake still on fails.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	st, pc := s.stream, s.pc
+	s.mu.Unlock()
+	s.finish(net.ErrClosed)
+	if st != nil {
+		st.Close()
+	}
+	if pc != nil {
+		pc.Close()
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/big"
+
+	"github.com/nobonobo/ssh-p2p/p2p"
+)
+
+// SPAKE2 over P-256 as in RFC 9382, used to turn a short code into a strong
+// secret that authenticates the DTLS fingerprints of both peers. A peer that
+// does not know the code learns nothing from the exchange that would let it
+// test guesses offline; each online guess costs it one attempt.
+//
+// crypto/ecdh does not expose point addition, so this uses crypto/elliptic.
+
+var errPAKE = errors.New("code mismatch")
+
+var (
+	spakeM = mustPoint("02886e2f97ace46e55ba9dd7242579f2993b64e16ef3dcab95afd497333d8fa12f")
+	spakeN = mustPoint("03d8bbd6c639c62937b04d997f38c3770719c629d7014d49a24b4f98baa1292b49")
+)
+
+type point struct{ x, y *big.Int }
+
+func mustPoint(s string) point {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), b)
+	if x == nil {
+		panic("pake: invalid point " + s)
+	}
+	return point{x, y}
+}
+
+// pake is one side of a SPAKE2 exchange.
+type pake struct {
+	client bool
+	w, x   *big.Int
+	msg    []byte // our share
+}
+
+// newPAKE starts an exchange for code. The client side sends the first
+// share, masked with M; the server side masks its share with N.
+func newPAKE(code string, client bool) (*pake, error) {
+	curve := elliptic.P256()
+	sum := sha256.Sum256([]byte("ssh-p2p code\n" + code))
+	w := new(big.Int).Mod(new(big.Int).SetBytes(sum[:]), curve.Params().N)
+	x, err := rand.Int(rand.Reader, curve.Params().N)
+	if err != nil {
+		return nil, err
+	}
+	mask := spakeN
+	if client {
+		mask = spakeM
+	}
+	gx, gy := curve.ScalarBaseMult(x.Bytes())
+	mx, my := curve.ScalarMult(mask.x, mask.y, w.Bytes())
+	px, py := curve.Add(gx, gy, mx, my)
+	return &pake{
+		client: client,
+		w:      w,
+		x:      x,
+		msg:    elliptic.Marshal(curve, px, py),
+	}, nil
+}
+
+// Message returns the share to send to the peer.
+func (p *pake) Message() string {
+	return base64.RawURLEncoding.EncodeToString(p.msg)
+}
+
+// Finish combines the peer's share with ours and returns the confirmation
+// keys of the client and server sides.
+func (p *pake) Finish(peer string) (kcA, kcB []byte, err error) {
+	curve := elliptic.P256()
+	b, err := base64.RawURLEncoding.DecodeString(peer)
+	if err != nil {
+		return nil, nil, errPAKE
+	}
+	qx, qy := elliptic.Unmarshal(curve, b)
+	if qx == nil {
+		return nil, nil, errPAKE
+	}
+	mask := spakeM
+	if p.client {
+		mask = spakeN
+	}
+	// K = x * (Q - w*mask)
+	mx, my := curve.ScalarMult(mask.x, mask.y, p.w.Bytes())
+	my = new(big.Int).Sub(curve.Params().P, my)
+	tx, ty := curve.Add(qx, qy, mx, my)
+	kx, ky := curve.ScalarMult(tx, ty, p.x.Bytes())
+	if kx.Sign() == 0 && ky.Sign() == 0 {
+		return nil, nil, errPAKE
+	}
+	pA, pB := p.msg, b
+	if !p.client {
+		pA, pB = b, p.msg
+	}
+	var tt []byte
+	for _, v := range [][]byte{
+		[]byte("client"), []byte("server"), pA, pB,
+		elliptic.Marshal(curve, kx, ky), p.w.Bytes(),
+	} {
+		tt = binary.LittleEndian.AppendUint64(tt, uint64(len(v)))
+		tt = append(tt, v...)
+	}
+	sum := sha256.Sum256(tt)
+	ka := sum[16:]
+	keys, err := hkdf.Key(sha256.New, ka, nil, "ConfirmationKeys", 32)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keys[:16], keys[16:], nil
+}
+
+// confirmBinding returns what the key confirmation covers of one side: the
+// DTLS fingerprint of its SDP and, if it offered the relay, the fingerprint
+// of its relay key.
+func confirmBinding(sdp, relay string) string {
+	fp := p2p.SDPFingerprint(sdp)
+	if relay != "" {
+		fp += " " + p2p.RelayFingerprint(relay)
+	}
+	return fp
+}
+
+// confirmFingerprints returns the key confirmation of kc over the offer and
+// answer DTLS fingerprints.
+func confirmFingerprints(kc []byte, offerFP, answerFP string) string {
+	mac := hmac.New(sha256.New, kc)
+	mac.Write([]byte(offerFP + "\n" + answerFP))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// checkConfirmation reports whether confirm is the key confirmation of kc
+// over the fingerprints.
+func checkConfirmation(kc []byte, offerFP, answerFP, confirm string) error {
+	want := confirmFingerprints(kc, offerFP, answerFP)
+	if !hmac.Equal([]byte(want), []byte(confirm)) {
+		return errPAKE
+	}
+	return nil
+}
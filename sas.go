@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// promptMu serializes confirmation prompts of concurrent sessions.
+var promptMu sync.Mutex
+
+// confirmSAS asks whether the session showing sas may be forwarded.
+var confirmSAS = promptSAS
+
+// promptSAS asks on the controlling terminal whether the session showing
+// sas may be forwarded.
+func promptSAS(key, peer, sas string) (bool, error) {
+	promptMu.Lock()
+	defer promptMu.Unlock()
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false, err
+	}
+	defer tty.Close()
+	fmt.Fprintf(tty, "session %s for key %s: does the peer show %s? [y/N] ", peer, key, sas)
+	line, err := bufio.NewReader(tty).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/nobonobo/ssh-p2p/p2p"
+)
+
+// Notices are control messages sent to the peer as string messages; tunnel
+// data is always sent as binary messages. The end of a tunnel is signaled
+// by the stream itself; see p2p.Conn.
+const (
+	// noticeShutdown tells the peer that this process is draining.
+	noticeShutdown = "shutdown"
+	// noticeProof carries a proof the peer must give before data flows:
+	// the server peer's identity proof, see proveIdentity, or the client
+	// peer's key confirmation of a short code, see confirmFingerprints.
+	noticeProof = "proof"
+	// noticeAuth carries the client peer's SSH key signatures; see
+	// signAuth.
+	noticeAuth = "auth"
+	// noticeError tells the client peer why the server peer gave up on the
+	// session, such as a failed dial of the target.
+	noticeError = "error"
+)
+
+// session is a tunnel between the stream of a p2p session and a TCP
+// connection.
+type session struct {
+	key  string
+	log  *slog.Logger
+	once sync.Once
+
+	mu     sync.Mutex
+	ps     *p2p.Session // nil until its handshake started, see setPeer
+	conn   net.Conn     // nil until dialed, see setConn
+	stream net.Conn     // nil until the handshake handed it over
+	closed bool
+}
+
+// setPeer sets the p2p session the tunnel runs over once its handshake
+// starts, and takes the peer's notices.
+func (s *session) setPeer(ps *p2p.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return net.ErrClosed
+	}
+	s.ps = ps
+	ps.OnNotice(s.handleNotice)
+	return nil
+}
+
+// setConn sets the local end of a session that was started without one.
+func (s *session) setConn(conn net.Conn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		conn.Close()
+		return net.ErrClosed
+	}
+	s.conn = conn
+	return nil
+}
+
+// setStream sets the stream the handshake handed over.
+func (s *session) setStream(st net.Conn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		st.Close()
+		return net.ErrClosed
+	}
+	s.stream = st
+	return nil
+}
+
+// connected logs the path of the established session with attrs.
+func (s *session) connected(attrs ...any) {
+	s.mu.Lock()
+	ps := s.ps
+	s.mu.Unlock()
+	attrs = append(attrs, "sas", ps.SAS(), "pair", ps.PairType(), "transport", ps.Transport())
+	s.log.Info("connected", attrs...)
+}
+
+// pipe copies data both ways, passing the end of each direction on as a
+// half-close, and closes the session once both are done or either fails.
+// Data received before pipe is buffered by the stream.
+func (s *session) pipe() {
+	s.mu.Lock()
+	conn, st := s.conn, s.stream
+	s.mu.Unlock()
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(conn, st)
+		if err == nil {
+			err = closeWrite(conn)
+		}
+		done <- err
+	}()
+	_, err := io.Copy(st, conn)
+	if err == nil {
+		err = closeWrite(st)
+	}
+	if err == nil {
+		err = <-done
+	}
+	if err != nil {
+		s.log.Debug("pipe ended", "err", err)
+	}
+	s.Close()
+}
+
+// closeWrite half-closes conn if it supports that, and closes it otherwise.
+func closeWrite(conn net.Conn) error {
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return conn.Close()
+}
+
+// notify sends a control message to the peer if the channel is up.
+func (s *session) notify(msg string) error {
+	s.mu.Lock()
+	ps := s.ps
+	s.mu.Unlock()
+	if ps == nil {
+		return nil
+	}
+	return ps.Notify(msg)
+}
+
+// handleNotice acts on a control message received from the peer. The
+// proofs the session expects never get here, see p2p.Session.Expect.
+func (s *session) handleNotice(msg string) {
+	msg, args, _ := strings.Cut(msg, " ")
+	switch msg {
+	case noticeShutdown:
+		s.log.Info("peer is shutting down")
+	case noticeError:
+		s.log.Error("peer failed", "err", args)
+		s.Close()
+	case noticeProof, noticeAuth:
+		s.log.Debug("proof not required", "notice", msg)
+	default:
+		s.log.Warn("unknown notice", "notice", msg)
+	}
+}
+
+// Close tears down both ends of the tunnel. The session leaves the set
+// before the p2p session is closed, since that may block on a dead peer.
+func (s *session) Close() {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		ps, conn, st := s.ps, s.conn, s.stream
+		s.mu.Unlock()
+		if st != nil {
+			st.Close()
+		}
+		if conn != nil {
+			conn.Close()
+		}
+		sessions.remove(s)
+		if ps != nil {
+			ps.Close()
+		}
+	})
+}
+
+// sessionSet tracks the live sessions of the process.
+type sessionSet struct {
+	mu      sync.Mutex
+	m       map[*session]struct{}
+	total   int
+	waiters []chan struct{}
+}
+
+var sessions = &sessionSet{m: map[*session]struct{}{}}
+
+func (ss *sessionSet) add(s *session) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.m[s] = struct{}{}
+	ss.total++
+}
+
+func (ss *sessionSet) remove(s *session) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	delete(ss.m, s)
+	if len(ss.m) == 0 {
+		for _, w := range ss.waiters {
+			close(w)
+		}
+		ss.waiters = nil
+	}
+}
+
+// list returns a snapshot of the live sessions.
+func (ss *sessionSet) list() []*session {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	l := make([]*session, 0, len(ss.m))
+	for s := range ss.m {
+		l = append(l, s)
+	}
+	return l
+}
+
+// drained returns a channel that is closed once no session is left.
+func (ss *sessionSet) drained() <-chan struct{} {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ch := make(chan struct{})
+	if len(ss.m) == 0 {
+		close(ch)
+	} else {
+		ss.waiters = append(ss.waiters, ch)
+	}
+	return ch
+}
+
+// served returns the number of sessions started since the process began.
+func (ss *sessionSet) served() int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.total
+}
+
+// count returns the number of live sessions for key.
+func (ss *sessionSet) count(key string) int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	n := 0
+	for s := range ss.m {
+		if s.key == key {
+			n++
+		}
+	}
+	return n
+}
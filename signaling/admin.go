@@ -0,0 +1,322 @@
+package signaling
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The admin API, served under /admin/ when Server.AdminToken is set, takes
+// the token as a bearer token or as the password of basic authentication,
+// which browsers prompt for:
+//
+//	GET  /admin/                dashboard
+//	GET  /admin/state           mailboxes, relays, recent errors and sources
+//	POST /admin/purge?mailbox=  drop a mailbox, ending the pulls waiting on it
+//	POST /admin/ban?ip=         refuse every request of a source
+//	POST /admin/unban?ip=
+//
+// Cross-origin browser requests are refused.
+
+const (
+	// recentErrors is how many failed requests the admin state keeps.
+	recentErrors = 50
+	// presenceTimeout is how long after its latest pull a mailbox counts
+	// as online; pullers poll again right after each pull ends.
+	presenceTimeout = 3 * pullWait
+	// idleTimeout is how long an idle source or mailbox is remembered.
+	idleTimeout = 10 * time.Minute
+)
+
+//go:embed admin.html
+var dashboard []byte
+
+// requestError is a failed request, as the admin state lists it.
+type requestError struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	Status int       `json:"status"`
+}
+
+// source counts the requests of a client address over the current and the
+// previous minute.
+type source struct {
+	start time.Time // of the current minute
+	cur   int
+	prev  int
+	total int
+	last  time.Time
+}
+
+// add counts a request at now.
+func (s *source) add(now time.Time) {
+	if s.start.IsZero() {
+		s.start = now
+	}
+	s.roll(now)
+	s.cur++
+	s.total++
+	s.last = now
+}
+
+// roll moves the minutes of s forward to now.
+func (s *source) roll(now time.Time) {
+	n := now.Sub(s.start) / time.Minute
+	switch {
+	case n == 0:
+		return
+	case n == 1:
+		s.prev = s.cur
+	default:
+		s.prev = 0
+	}
+	s.cur = 0
+	s.start = s.start.Add(n * time.Minute)
+}
+
+// rate estimates the requests per minute of s at now, weighting the
+// previous minute by how much of it still is within the last one.
+func (s source) rate(now time.Time) float64 {
+	s.roll(now)
+	left := 1 - float64(now.Sub(s.start))/float64(time.Minute)
+	return float64(s.prev)*left + float64(s.cur)
+}
+
+// stats are what the admin state reports besides the mailboxes.
+type stats struct {
+	mu      sync.Mutex
+	errors  []requestError // the latest last
+	sources map[string]*source
+	banned  map[string]time.Time // to when they were banned
+	pruned  time.Time
+}
+
+// count counts a request of ip and reports whether a minute passed since
+// idle entries were last pruned.
+func (st *stats) count(ip string, now time.Time) (prune bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	src := st.sources[ip]
+	if src == nil {
+		src = &source{}
+		st.sources[ip] = src
+	}
+	src.add(now)
+	if now.Sub(st.pruned) < time.Minute {
+		return false
+	}
+	st.pruned = now
+	for ip, src := range st.sources {
+		if now.Sub(src.last) > idleTimeout {
+			delete(st.sources, ip)
+		}
+	}
+	return true
+}
+
+func (st *stats) fail(e requestError) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if len(st.errors) == recentErrors {
+		st.errors = append(st.errors[:0], st.errors[1:]...)
+	}
+	st.errors = append(st.errors, e)
+}
+
+func (st *stats) isBanned(ip string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	_, ok := st.banned[ip]
+	return ok
+}
+
+func (st *stats) ban(ip string, banned bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if banned {
+		st.banned[ip] = time.Now()
+	} else {
+		delete(st.banned, ip)
+	}
+}
+
+// prune forgets the mailboxes nobody pulled for a while; a mailbox without
+// pulls drops its messages anyway.
+func (s *Server) prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, m := range s.mailboxes {
+		if m.waiting == 0 && now.Sub(m.lastPull) > idleTimeout {
+			delete(s.mailboxes, id)
+		}
+	}
+}
+
+// purge drops the mailbox id, ending the pulls waiting on it, and reports
+// whether there was one.
+func (s *Server) purge(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.mailboxes[id]
+	if m == nil {
+		return false
+	}
+	close(m.purged)
+	delete(s.mailboxes, id)
+	return true
+}
+
+type mailboxState struct {
+	ID     string `json:"id"`
+	Online bool   `json:"online"`
+	// Waiting is the number of pulls waiting for a message.
+	Waiting   int       `json:"waiting"`
+	LastPull  time.Time `json:"last_pull"`
+	Delivered int       `json:"delivered"`
+	// Dropped counts the messages pushed while no pull waited.
+	Dropped int `json:"dropped"`
+}
+
+type sourceState struct {
+	IP string `json:"ip"`
+	// Rate is in requests per minute.
+	Rate   float64    `json:"rate"`
+	Total  int        `json:"total"`
+	Last   *time.Time `json:"last,omitempty"`
+	Banned *time.Time `json:"banned,omitempty"`
+}
+
+// tenantState is the usage of a tenant, next to its quotas.
+type tenantState struct {
+	Name         string  `json:"name"`
+	Mailboxes    int     `json:"mailboxes"`
+	MaxMailboxes int     `json:"max_mailboxes,omitempty"`
+	Relays       int     `json:"relays"`
+	MaxRelays    int     `json:"max_relays,omitempty"`
+	Rate         float64 `json:"rate"`
+	MaxRate      int     `json:"max_rate,omitempty"`
+}
+
+type adminState struct {
+	Time      time.Time      `json:"time"`
+	Mailboxes []mailboxState `json:"mailboxes"`
+	// RelaysWaiting are relay requests waiting for their peer, and
+	// RelaysActive the paired sessions.
+	RelaysWaiting int            `json:"relays_waiting"`
+	RelaysActive  int            `json:"relays_active"`
+	Errors        []requestError `json:"errors"`
+	Sources       []sourceState  `json:"sources"`
+	Tenants       []tenantState  `json:"tenants,omitempty"`
+}
+
+func (s *Server) state() *adminState {
+	now := time.Now()
+	st := &adminState{Time: now, Mailboxes: []mailboxState{}, Sources: []sourceState{}}
+	s.mu.Lock()
+	for id, m := range s.mailboxes {
+		st.Mailboxes = append(st.Mailboxes, mailboxState{
+			ID:        id,
+			Online:    m.waiting > 0 || now.Sub(m.lastPull) < presenceTimeout,
+			Waiting:   m.waiting,
+			LastPull:  m.lastPull,
+			Delivered: m.delivered,
+			Dropped:   m.dropped,
+		})
+	}
+	for name, u := range s.usage {
+		st.Tenants = append(st.Tenants, tenantState{
+			Name:         name,
+			Mailboxes:    u.mailboxes,
+			MaxMailboxes: u.tenant.Mailboxes,
+			Relays:       (u.relays + 1) / 2,
+			MaxRelays:    u.tenant.Relays,
+			Rate:         u.requests.rate(now),
+			MaxRate:      u.tenant.Rate,
+		})
+	}
+	s.mu.Unlock()
+	sort.Slice(st.Tenants, func(i, j int) bool { return st.Tenants[i].Name < st.Tenants[j].Name })
+	sort.Slice(st.Mailboxes, func(i, j int) bool {
+		return st.Mailboxes[i].LastPull.After(st.Mailboxes[j].LastPull)
+	})
+
+	s.relayMu.Lock()
+	st.RelaysWaiting, st.RelaysActive = len(s.relays), s.relayActive/2
+	s.relayMu.Unlock()
+
+	s.stats.mu.Lock()
+	st.Errors = append([]requestError{}, s.stats.errors...)
+	for ip, src := range s.stats.sources {
+		last := src.last
+		ss := sourceState{IP: ip, Rate: src.rate(now), Total: src.total, Last: &last}
+		if t, ok := s.stats.banned[ip]; ok {
+			ss.Banned = &t
+		}
+		st.Sources = append(st.Sources, ss)
+	}
+	for ip, t := range s.stats.banned {
+		if s.stats.sources[ip] == nil {
+			st.Sources = append(st.Sources, sourceState{IP: ip, Banned: &t})
+		}
+	}
+	s.stats.mu.Unlock()
+	sort.Slice(st.Sources, func(i, j int) bool {
+		if st.Sources[i].Rate != st.Sources[j].Rate {
+			return st.Sources[i].Rate > st.Sources[j].Rate
+		}
+		return st.Sources[i].IP < st.Sources[j].IP
+	})
+	return st
+}
+
+// adminAuthorized reports whether r bears the admin token.
+func (s *Server) adminAuthorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, token, ok = r.BasicAuth()
+	}
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.AdminToken)) == 1
+}
+
+func (s *Server) admin(w http.ResponseWriter, r *http.Request) {
+	if s.AdminToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.adminAuthorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="signaling admin"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	get := r.Method == http.MethodGet || r.Method == http.MethodHead
+	switch {
+	case r.URL.Path == "" && get:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+		w.Write(dashboard)
+	case r.URL.Path == "state" && get:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.state())
+	case r.URL.Path == "purge" && r.Method == http.MethodPost:
+		if !s.purge(r.FormValue("mailbox")) {
+			http.NotFound(w, r)
+		}
+	case (r.URL.Path == "ban" || r.URL.Path == "unban") && r.Method == http.MethodPost:
+		ip := r.FormValue("ip")
+		if ip == "" {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		s.stats.ban(ip, r.URL.Path == "ban")
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,160 @@
+package signaling
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func adminRequest(t *testing.T, method, url, token string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return res
+}
+
+func adminStateOf(t *testing.T, base string) *adminState {
+	t.Helper()
+	req, _ := http.NewRequest("GET", base+"/admin/state", nil)
+	req.SetBasicAuth("admin", "secret")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var st adminState
+	if err := json.NewDecoder(res.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+	return &st
+}
+
+func TestAdminAuth(t *testing.T) {
+	s := NewServer()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	if res := adminRequest(t, "GET", ts.URL+"/admin/state", ""); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("without a token configured: %s", res.Status)
+	}
+	s.AdminToken = "secret"
+	for token, code := range map[string]int{"": http.StatusUnauthorized, "wrong": http.StatusUnauthorized, "secret": http.StatusOK} {
+		if res := adminRequest(t, "GET", ts.URL+"/admin/", token); res.StatusCode != code {
+			t.Errorf("token %q: %s, want %d", token, res.Status, code)
+		}
+	}
+
+	req, _ := http.NewRequest("POST", ts.URL+"/admin/ban?ip=192.0.2.1", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Sec-Fetch-Site", "cross-site")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden || s.stats.isBanned("192.0.2.1") {
+		t.Fatalf("cross-site ban: %s", res.Status)
+	}
+}
+
+func TestAdminPurge(t *testing.T) {
+	s := NewServer()
+	s.AdminToken = "secret"
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	pulled := make(chan int, 1)
+	go func() {
+		res, err := http.Get(ts.URL + "/pull/box")
+		if err != nil {
+			pulled <- 0
+			return
+		}
+		res.Body.Close()
+		pulled <- res.StatusCode
+	}()
+	for i := 0; ; i++ {
+		st := adminStateOf(t, ts.URL)
+		if len(st.Mailboxes) == 1 && st.Mailboxes[0].Waiting == 1 {
+			if m := st.Mailboxes[0]; m.ID != "box" || !m.Online {
+				t.Fatalf("got %+v", m)
+			}
+			break
+		}
+		if i == 100 {
+			t.Fatal("the pull is not listed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if res := adminRequest(t, "POST", ts.URL+"/admin/purge?mailbox=box", "secret"); res.StatusCode != http.StatusOK {
+		t.Fatalf("purge: %s", res.Status)
+	}
+	select {
+	case code := <-pulled:
+		if code != http.StatusRequestTimeout {
+			t.Fatalf("purged pull answered %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the pull outlived the purge")
+	}
+	if st := adminStateOf(t, ts.URL); len(st.Mailboxes) != 0 {
+		t.Fatalf("purged mailbox listed: %+v", st.Mailboxes)
+	}
+	if res := adminRequest(t, "POST", ts.URL+"/admin/purge?mailbox=box", "secret"); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("purge of a missing mailbox: %s", res.Status)
+	}
+}
+
+func TestAdminBan(t *testing.T) {
+	s := NewServer()
+	s.AdminToken = "secret"
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	push := func() int {
+		res, err := http.Post(ts.URL+"/push/box", "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	adminRequest(t, "POST", ts.URL+"/admin/ban?ip=127.0.0.1", "secret")
+	if code := push(); code != http.StatusForbidden {
+		t.Fatalf("banned push answered %d", code)
+	}
+	st := adminStateOf(t, ts.URL)
+	if len(st.Sources) != 1 || st.Sources[0].Banned == nil || st.Sources[0].Total < 3 || st.Sources[0].Rate < 3 {
+		t.Fatalf("sources %+v", st.Sources)
+	}
+	if len(st.Errors) != 1 || st.Errors[0].Status != http.StatusForbidden || st.Errors[0].Path != "/push/box" {
+		t.Fatalf("errors %+v", st.Errors)
+	}
+	adminRequest(t, "POST", ts.URL+"/admin/unban?ip=127.0.0.1", "secret")
+	if code := push(); code != http.StatusOK {
+		t.Fatalf("unbanned push answered %d", code)
+	}
+}
+
+func TestSourceRate(t *testing.T) {
+	now := time.Now()
+	s := source{start: now.Add(-90 * time.Second), cur: 60}
+	if r := s.rate(now); r < 29 || r > 31 {
+		t.Fatalf("rate %v half a minute into the next minute, want 30", r)
+	}
+	if r := s.rate(now.Add(time.Minute)); r != 0 {
+		t.Fatalf("rate %v two minutes later", r)
+	}
+}
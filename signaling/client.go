@@ -0,0 +1,277 @@
+package signaling
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+const (
+	// DefaultPushTimeout bounds a push request.
+	DefaultPushTimeout = 10 * time.Second
+	// DefaultPullTimeout bounds a pull request; it must exceed the time
+	// the server holds a pull, 5s for Server.
+	DefaultPullTimeout = 30 * time.Second
+	// DefaultMinBackoff and DefaultMaxBackoff bound the wait between
+	// failed requests, doubled after each failure.
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// pushAttempts bounds the requests of a push that keeps failing.
+const pushAttempts = 4
+
+// maxMessage bounds the body of a pull response.
+const maxMessage = 1 << 20
+
+// ErrKeyExpired is returned for the mailboxes of expired keys, which the
+// server no longer serves.
+var ErrKeyExpired = errors.New("signaling: key expired")
+
+// StatusError is a response of the signaling server other than 200 OK.
+type StatusError struct {
+	Op     string // push or pull
+	Code   int
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("signaling: %s: http status %s", e.Op, e.Status)
+}
+
+// Is makes a 410 Gone match ErrKeyExpired.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrKeyExpired && e.Code == http.StatusGone
+}
+
+// Temporary reports whether the request may succeed if repeated: the
+// server refuses malformed requests, unknown tokens, forbidden mailboxes
+// and the mailboxes of expired keys for good, while other failures may
+// come from a restart or a proxy.
+func (e *StatusError) Temporary() bool {
+	switch e.Code {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusGone:
+		return false
+	}
+	return true
+}
+
+// Client talks to a signaling server. The zero value uses URI with the
+// defaults below.
+type Client struct {
+	// Base is the base URL of the signaling server, URI if empty.
+	Base string
+	// HTTPClient makes the requests, http.DefaultClient if nil. Its own
+	// Timeout, if any, must exceed PullTimeout.
+	HTTPClient *http.Client
+	// PushTimeout and PullTimeout bound each request,
+	// DefaultPushTimeout and DefaultPullTimeout if zero.
+	PushTimeout time.Duration
+	PullTimeout time.Duration
+	// MinBackoff and MaxBackoff bound the wait after a failed request,
+	// DefaultMinBackoff and DefaultMaxBackoff if zero.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func (c *Client) base() string {
+	if c.Base == "" {
+		return URI
+	}
+	return c.Base
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// Push posts info to the mailbox dst, retrying failed requests with
+// backoff until ctx is done. A message pushed to a mailbox nobody pulls
+// is dropped by the server. It returns a *StatusError for a response
+// other than 200 OK.
+func (c *Client) Push(ctx context.Context, dst string, info ConnectInfo) error {
+	body, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	b := c.newBackoff()
+	for attempt := 1; ; attempt++ {
+		err := c.push(ctx, dst, body)
+		var se *StatusError
+		if err == nil || ctx.Err() != nil || attempt == pushAttempts ||
+			errors.As(err, &se) && !se.Temporary() {
+			return err
+		}
+		if err := b.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) push(ctx context.Context, dst string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, orDefault(c.PushTimeout, DefaultPushTimeout))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", c.base()+path.Join("/", "push", dst), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, maxMessage))
+	if res.StatusCode != http.StatusOK {
+		return &StatusError{Op: "push", Code: res.StatusCode, Status: res.Status}
+	}
+	return nil
+}
+
+// Pull long-polls the mailboxes ids until ctx is done. With several ids a
+// single request waits on all of them and the Destination of each info
+// tells them apart. Failed requests are retried with backoff; the channel
+// closes once ctx is done or the server refuses the mailboxes, such as
+// those of expired keys, and logger tells why.
+func (c *Client) Pull(ctx context.Context, logger *slog.Logger, ids ...string) <-chan ConnectInfo {
+	ch := make(chan ConnectInfo)
+	go func() {
+		defer close(ch)
+		b := c.newBackoff()
+		for {
+			info, err := c.pull(ctx, ids)
+			var se *StatusError
+			switch {
+			case ctx.Err() != nil:
+				return
+			case errors.As(err, &se) && se.Code == http.StatusRequestTimeout:
+				// Nothing arrived while the server held the request.
+				b.reset()
+				continue
+			case errors.As(err, &se) && !se.Temporary():
+				logger.Error("pull stopped", "err", err)
+				return
+			case err != nil:
+				logger.Warn("pull failed", "err", err, "retry", b.next())
+				if b.wait(ctx) != nil {
+					return
+				}
+				continue
+			}
+			b.reset()
+			if info.Destination == "" {
+				info.Destination = ids[0]
+			}
+			if info.Source == "" || info.SDP == "" {
+				continue
+			}
+			select {
+			case ch <- info:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func (c *Client) pull(ctx context.Context, ids []string) (ConnectInfo, error) {
+	var info ConnectInfo
+	ctx, cancel := context.WithTimeout(ctx, orDefault(c.PullTimeout, DefaultPullTimeout))
+	defer cancel()
+	uri := c.base() + path.Join("/", "pull", ids[0])
+	if len(ids) > 1 {
+		uri = c.base() + "/pull/?" + url.Values{"id": ids}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return info, err
+	}
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return info, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(res.Body, maxMessage))
+		return info, &StatusError{Op: "pull", Code: res.StatusCode, Status: res.Status}
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxMessage)).Decode(&info); err != nil {
+		return info, fmt.Errorf("signaling: pull: %w", err)
+	}
+	return info, nil
+}
+
+// backoff is the wait after failed requests: it doubles from min to max
+// after each failure, each wait drawn between half of it and all of it so
+// that peers failing together do not retry together.
+type backoff struct {
+	min, max, d time.Duration
+}
+
+func (c *Client) newBackoff() *backoff {
+	b := &backoff{
+		min: orDefault(c.MinBackoff, DefaultMinBackoff),
+		max: orDefault(c.MaxBackoff, DefaultMaxBackoff),
+	}
+	b.reset()
+	return b
+}
+
+func (b *backoff) reset() {
+	b.d = b.min
+}
+
+// next returns the bound of the next wait.
+func (b *backoff) next() time.Duration {
+	return b.d
+}
+
+// wait sleeps for the next wait or until ctx is done, and doubles it.
+func (b *backoff) wait(ctx context.Context) error {
+	d := b.d/2 + time.Duration(rand.Int63n(int64(b.d/2)+1))
+	if b.d *= 2; b.d > b.max {
+		b.d = b.max
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Push posts info to the mailbox dst of the signaling server at base with
+// a default Client.
+func Push(base, dst string, info ConnectInfo) error {
+	c := &Client{Base: base}
+	return c.Push(context.Background(), dst, info)
+}
+
+// Pull long-polls the mailboxes ids of the signaling server at base with a
+// default Client.
+func Pull(ctx context.Context, base string, logger *slog.Logger, ids ...string) <-chan ConnectInfo {
+	c := &Client{Base: base}
+	return c.Pull(ctx, logger, ids...)
+}
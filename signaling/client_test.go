@@ -0,0 +1,79 @@
+package signaling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testClient(t *testing.T, h http.HandlerFunc) *Client {
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	return &Client{Base: ts.URL, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+}
+
+func TestPullRetries(t *testing.T) {
+	var n atomic.Int32
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch n.Add(1) {
+		case 1:
+			http.Error(w, "", http.StatusRequestTimeout)
+		case 2:
+			http.Error(w, "", http.StatusServiceUnavailable)
+		case 3:
+			io.WriteString(w, "not json")
+		default:
+			json.NewEncoder(w).Encode(ConnectInfo{Source: "a", SDP: "b"})
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	select {
+	case v := <-c.Pull(ctx, slog.Default(), "box"):
+		if v.Source != "a" || v.Destination != "box" {
+			t.Fatalf("got %+v", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message")
+	}
+}
+
+func TestPullStops(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "", http.StatusGone)
+	})
+	select {
+	case _, ok := <-c.Pull(context.Background(), slog.Default(), "box"):
+		if ok {
+			t.Fatal("message from a gone mailbox")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pull of a gone mailbox goes on")
+	}
+}
+
+func TestPushErrors(t *testing.T) {
+	var n atomic.Int32
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1) == 1 {
+			http.Error(w, "", http.StatusBadGateway)
+			return
+		}
+		http.Error(w, "", http.StatusGone)
+	})
+	err := c.Push(context.Background(), "box", ConnectInfo{})
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != http.StatusGone || !errors.Is(err, ErrKeyExpired) {
+		t.Fatalf("got %v", err)
+	}
+	if n.Load() != 2 {
+		t.Fatalf("%d requests, want a retry after 502 only", n.Load())
+	}
+}
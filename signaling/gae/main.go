@@ -1,78 +1,78 @@
-package main
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
-	"sync"
-	"time"
-
-	"github.com/nobonobo/ssh-p2p/signaling"
-)
-
-var (
-	// Sets your Google Cloud Platform project ID.
-	projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
-	res       = map[string]chan signaling.ConnectInfo{}
-	mu        sync.RWMutex
-)
-
-func main() {
-	http.Handle("/pull/", http.StripPrefix("/pull/", pullData()))
-	http.Handle("/push/", http.StripPrefix("/push/", pushData()))
-
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-		log.Printf("Defaulting to port %s", port)
-	}
-
-	log.Printf("Listening on port %s", port)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", port), nil))
-}
-
-func pushData() http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var info signaling.ConnectInfo
-		if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
-			log.Print("json decode failed:", err)
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-			return
-		}
-		mu.RLock()
-		defer mu.RUnlock()
-		select {
-		default:
-		case res[r.URL.Path] <- info:
-		}
-	})
-}
-
-func pullData() http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		mu.Lock()
-		ch := res[r.URL.Path]
-		if ch == nil {
-			ch = make(chan signaling.ConnectInfo)
-			res[r.URL.Path] = ch
-		}
-		mu.Unlock()
-		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
-		defer cancel()
-		select {
-		case <-ctx.Done():
-			http.Error(w, ``, http.StatusRequestTimeout)
-			return
-		case v := <-ch:
-			w.Header().Add("Content-Type", "application/json")
-			if err := json.NewEncoder(w).Encode(v); err != nil {
-				log.Print("json encode failed:", err)
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-				return
-			}
-		}
-	})
-}
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/nobonobo/ssh-p2p/signaling"
+)
+
+var (
+	// Sets your Google Cloud Platform project ID.
+	projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+)
+
+type listFlags []string
+
+func (l *listFlags) String() string     { return fmt.Sprint(*l) }
+func (l *listFlags) Set(v string) error { *l = append(*l, v); return nil }
+
+func main() {
+	// App Engine terminates TLS itself; these are for a server of your own.
+	var conf signaling.ServerTLS
+	var acl, tenants string
+	flag.StringVar(&conf.CertFile, "tls-cert", "", "serve TLS with this PEM certificate chain")
+	flag.StringVar(&conf.KeyFile, "tls-key", "", "PEM private key of -tls-cert")
+	flag.Var((*listFlags)(&conf.ClientCAFiles), "client-ca", "require client certificates issued by this PEM CA bundle (repeatable)")
+	flag.StringVar(&acl, "acl", "", "file mapping client certificate identities to the mailboxes they may use")
+	flag.StringVar(&tenants, "tenants", "", "TOML file of the tenants sharing the server, with their tokens and quotas")
+	flag.Parse()
+
+	s := signaling.NewServer()
+	// The admin API and dashboard under /admin/ take this token.
+	s.AdminToken = os.Getenv("ADMIN_TOKEN")
+	if projectID != "" {
+		// App Engine's front end is the remote address of every request.
+		s.ClientIP = func(r *http.Request) string {
+			return r.Header.Get("X-Appengine-User-Ip")
+		}
+	}
+	if acl != "" {
+		if len(conf.ClientCAFiles) == 0 {
+			log.Fatal("-acl needs -client-ca")
+		}
+		a, err := signaling.LoadACL(acl)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s.Authorize = a.Authorize
+	}
+	if tenants != "" {
+		t, err := signaling.LoadTenants(tenants)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s.Tenants = t
+	}
+	http.Handle("/", s)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+		log.Printf("Defaulting to port %s", port)
+	}
+
+	log.Printf("Listening on port %s", port)
+	if conf.CertFile == "" && conf.KeyFile == "" && len(conf.ClientCAFiles) == 0 {
+		log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", port), nil))
+	}
+	tc, err := conf.Config()
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &http.Server{Addr: fmt.Sprintf(":%s", port), TLSConfig: tc}
+	log.Fatal(srv.ListenAndServeTLS("", ""))
+}
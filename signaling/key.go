@@ -0,0 +1,69 @@
+package signaling
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key is a connection key. It doubles as the name of the server peer's
+// mailbox, so the limits embedded in it cannot be altered by a client
+// without addressing a different mailbox.
+//
+// The text form is the id optionally followed by limits, each introduced by
+// a dot and a letter:
+//
+//	6ee87ebb-2938-47f9-8577-e8fd4aa3988c.e1792391191.u1
+//
+// where "e" is the expiry in unix seconds and "u" the number of sessions
+// the key may be used for.
+type Key struct {
+	ID      string
+	Expires time.Time // zero means never
+	Uses    int       // 0 means unlimited
+}
+
+// ParseKey parses the text form of a key.
+func ParseKey(s string) (Key, error) {
+	parts := strings.Split(s, ".")
+	k := Key{ID: parts[0]}
+	if k.ID == "" {
+		return Key{}, fmt.Errorf("key %q: empty id", s)
+	}
+	for _, p := range parts[1:] {
+		if len(p) < 2 {
+			return Key{}, fmt.Errorf("key %q: invalid limit %q", s, p)
+		}
+		n, err := strconv.ParseInt(p[1:], 10, 64)
+		if err != nil || n <= 0 {
+			return Key{}, fmt.Errorf("key %q: invalid limit %q", s, p)
+		}
+		switch p[0] {
+		case 'e':
+			k.Expires = time.Unix(n, 0)
+		case 'u':
+			k.Uses = int(n)
+		default:
+			return Key{}, fmt.Errorf("key %q: unknown limit %q", s, p)
+		}
+	}
+	return k, nil
+}
+
+// String returns the text form of k.
+func (k Key) String() string {
+	s := k.ID
+	if !k.Expires.IsZero() {
+		s += ".e" + strconv.FormatInt(k.Expires.Unix(), 10)
+	}
+	if k.Uses > 0 {
+		s += ".u" + strconv.Itoa(k.Uses)
+	}
+	return s
+}
+
+// Expired reports whether k is no longer valid at now.
+func (k Key) Expired(now time.Time) bool {
+	return !k.Expires.IsZero() && !now.Before(k.Expires)
+}
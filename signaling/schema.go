@@ -1,10 +1,27 @@
-package signaling
-
-// URI default signaling server
-const URI = "https://nobo-signaling.appspot.com"
-
-// ConnectInfo SDP by offer or answer
-type ConnectInfo struct {
-	Source string `json:"source"`
-	SDP    string `json:"sdp"`
-}
+package signaling
+
+// URI default signaling server
+const URI = "https://nobo-signaling.appspot.com"
+
+// ConnectInfo SDP by offer or answer
+type ConnectInfo struct {
+	Source string `json:"source"`
+	SDP    string `json:"sdp"`
+	// Destination is the mailbox the info was pulled from; it is set by
+	// the signaling server when several mailboxes are pulled at once.
+	Destination string `json:"destination,omitempty"`
+	// PAKE is the password-authenticated key exchange message of a peer
+	// connecting with a short code, and Confirm the answering peer's key
+	// confirmation over both DTLS fingerprints.
+	PAKE    string `json:"pake,omitempty"`
+	Confirm string `json:"confirm,omitempty"`
+	// Relay is the peer's public key for a relayed stream, offered in
+	// case the peers cannot connect directly.
+	Relay string `json:"relay,omitempty"`
+	// SAS is the offering peer's commitment to the nonce of the short
+	// authentication string, or the answering peer's nonce.
+	SAS string `json:"sas,omitempty"`
+	// Channel names the DataChannel an offer opens when it is not the
+	// data forwarded to the answering peer's target, such as "control".
+	Channel string `json:"channel,omitempty"`
+}
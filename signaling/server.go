@@ -0,0 +1,488 @@
+package signaling
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// pullWait bounds the wait of a pull request for a message.
+	pullWait = 5 * time.Second
+	// relayWait bounds the wait of a relay request for its peer.
+	relayWait = 30 * time.Second
+)
+
+// Server is a signaling server: it serves /push/ and /pull/ mailboxes for
+// ConnectInfo messages, and /relay/ for the sessions that cannot connect
+// directly. A message pushed to a mailbox nobody pulls is dropped.
+type Server struct {
+	// Authorize, if set, tells whether a request may push to, pull or
+	// relay through a mailbox; the others are answered 403 Forbidden.
+	Authorize func(r *http.Request, mailbox string) bool
+	// AdminToken, if set, enables the admin API and dashboard under
+	// /admin/ for the requests bearing it; see admin.go.
+	AdminToken string
+	// ClientIP returns the source address of a request, the host of its
+	// RemoteAddr if nil. Behind a proxy, it takes it from a header the
+	// proxy sets.
+	ClientIP func(r *http.Request) string
+	// Tenants, if set, splits the mailboxes among tenants and refuses the
+	// clients without the token of one.
+	Tenants *Tenants
+
+	mux *http.ServeMux
+
+	mu        sync.Mutex
+	mailboxes map[string]*mailbox // by Tenant.mailbox
+	usage     map[string]*usage   // by tenant name
+
+	relayMu     sync.Mutex
+	relays      map[string]chan *relayEnd
+	relayActive int // paired relay requests, two per session
+
+	stats stats
+}
+
+// mailbox is where pulls wait for a message. Nothing is queued: a message
+// pushed while no pull waits is dropped.
+type mailbox struct {
+	ch     chan ConnectInfo
+	purged chan struct{} // closed once an admin purges the mailbox
+
+	waiting   int       // pulls waiting
+	lastPull  time.Time // start or end of the latest pull
+	delivered int
+	dropped   int
+}
+
+// NewServer returns a signaling server with empty mailboxes.
+func NewServer() *Server {
+	s := &Server{
+		mux:       http.NewServeMux(),
+		mailboxes: map[string]*mailbox{},
+		usage:     map[string]*usage{},
+		relays:    map[string]chan *relayEnd{},
+		stats: stats{
+			sources: map[string]*source{},
+			banned:  map[string]time.Time{},
+		},
+	}
+	s.mux.Handle("/pull/", http.StripPrefix("/pull/", http.HandlerFunc(s.pull)))
+	s.mux.Handle("/push/", http.StripPrefix("/push/", http.HandlerFunc(s.push)))
+	s.mux.Handle("/relay/", http.StripPrefix("/relay/", http.HandlerFunc(s.relay)))
+	s.mux.Handle("/admin/", http.StripPrefix("/admin/", http.NewCrossOriginProtection().Handler(http.HandlerFunc(s.admin))))
+	return s
+}
+
+// ServeHTTP serves r, refusing banned sources but for the admin API, and
+// accounts for it in the admin statistics.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ip := s.clientIP(r)
+	now := time.Now()
+	if s.stats.count(ip, now) {
+		s.prune(now)
+	}
+	sw := &statusWriter{ResponseWriter: w, code: http.StatusOK}
+	if s.stats.isBanned(ip) && !strings.HasPrefix(r.URL.Path, "/admin/") {
+		http.Error(sw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	} else {
+		s.mux.ServeHTTP(sw, r)
+	}
+	if sw.code >= 400 && sw.code != http.StatusRequestTimeout {
+		s.stats.fail(requestError{Time: now, Source: ip, Method: r.Method, Path: r.URL.Path, Status: sw.code})
+	}
+}
+
+func (s *Server) clientIP(r *http.Request) string {
+	if s.ClientIP != nil {
+		return s.ClientIP(r)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// statusWriter records the status code of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	code   int
+	header bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.header {
+		w.code, w.header = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// expired reports whether id is a connection key past its expiry.
+func expired(id string) bool {
+	k, err := ParseKey(id)
+	return err == nil && k.Expired(time.Now())
+}
+
+// usage is what a tenant takes of its quotas.
+type usage struct {
+	tenant    *Tenant // as of its latest request
+	mailboxes int     // with pulls waiting
+	relays    int     // relay requests, two per session
+	requests  source
+}
+
+// usageOf returns the usage of t, with s.mu held.
+func (s *Server) usageOf(t *Tenant) *usage {
+	u := s.usage[t.Name]
+	if u == nil {
+		u = &usage{}
+		s.usage[t.Name] = u
+	}
+	u.tenant = t
+	return u
+}
+
+// tenant returns the tenant of r, nil without Tenants. It answers 401
+// Unauthorized to a request without a known token and 429 Too Many
+// Requests to one over the rate of its tenant, and then reports false.
+func (s *Server) tenant(w http.ResponseWriter, r *http.Request) (*Tenant, bool) {
+	if s.Tenants == nil {
+		return nil, true
+	}
+	t := s.Tenants.lookup(r)
+	if t == nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="signaling"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return nil, false
+	}
+	now := time.Now()
+	s.mu.Lock()
+	u := s.usageOf(t)
+	over := t.Rate > 0 && u.requests.rate(now) >= float64(t.Rate)
+	if !over {
+		u.requests.add(now)
+	}
+	s.mu.Unlock()
+	if over {
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return nil, false
+	}
+	return t, true
+}
+
+// authorized answers 403 Forbidden unless the request may use all of
+// mailboxes.
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request, mailboxes ...string) bool {
+	if s.Authorize == nil {
+		return true
+	}
+	for _, id := range mailboxes {
+		if !s.Authorize(r, id) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) push(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.tenant(w, r)
+	if !ok {
+		return
+	}
+	if !s.authorized(w, r, r.URL.Path) {
+		return
+	}
+	if expired(r.URL.Path) {
+		http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+		return
+	}
+	var info ConnectInfo
+	if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+		log.Print("json decode failed:", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.mailboxes[t.mailbox(r.URL.Path)]
+	if m == nil {
+		return
+	}
+	select {
+	default:
+		m.dropped++
+	case m.ch <- info:
+		m.delivered++
+	}
+}
+
+// pull waits for a message on the mailbox named by the path, or on any of
+// the mailboxes given as "id" query parameters when the path is empty.
+// Mailboxes of expired keys are skipped.
+func (s *Server) pull(w http.ResponseWriter, r *http.Request) {
+	query := []string{r.URL.Path}
+	if r.URL.Path == "" {
+		query = r.URL.Query()["id"]
+	}
+	if len(query) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	t, ok := s.tenant(w, r)
+	if !ok {
+		return
+	}
+	if !s.authorized(w, r, query...) {
+		return
+	}
+	var ids []string
+	for _, id := range query {
+		if !expired(id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), pullWait)
+	defer cancel()
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	}
+	boxes := make([]*mailbox, len(ids))
+	s.mu.Lock()
+	if t != nil && t.Mailboxes > 0 {
+		n := s.usageOf(t).mailboxes
+		for _, id := range ids {
+			if m := s.mailboxes[t.mailbox(id)]; m == nil || m.waiting == 0 {
+				n++
+			}
+		}
+		if n > t.Mailboxes {
+			s.mu.Unlock()
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+	}
+	for i, id := range ids {
+		m := s.mailboxes[t.mailbox(id)]
+		if m == nil {
+			m = &mailbox{ch: make(chan ConnectInfo), purged: make(chan struct{})}
+			s.mailboxes[t.mailbox(id)] = m
+		}
+		if m.waiting == 0 && t != nil {
+			s.usageOf(t).mailboxes++
+		}
+		m.waiting++
+		m.lastPull = time.Now()
+		boxes[i] = m
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(m.ch)})
+	}
+	for _, m := range boxes {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(m.purged)})
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, m := range boxes {
+			m.waiting--
+			m.lastPull = time.Now()
+			if m.waiting == 0 && t != nil {
+				s.usageOf(t).mailboxes--
+			}
+		}
+	}()
+	chosen, recv, _ := reflect.Select(cases)
+	switch {
+	case chosen == 0 || chosen > len(ids):
+		// Timed out or purged: the puller polls again.
+		http.Error(w, ``, http.StatusRequestTimeout)
+		return
+	default:
+		v := recv.Interface().(ConnectInfo)
+		v.Destination = ids[chosen-1]
+		w.Header().Add("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			log.Print("json encode failed:", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// relayEnd is one of the two requests paired by the relay.
+type relayEnd struct {
+	w     io.Writer                // the response, or the WebSocket
+	rc    *http.ResponseController // nil for a WebSocket
+	ready chan struct{}            // closed once w is set and answered
+	done  chan struct{}            // closed once the handler returns
+
+	mu     sync.Mutex
+	closed bool // the handler returned
+}
+
+// Write copies data from the peer into the response.
+func (e *relayEnd) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return 0, http.ErrHandlerTimeout
+	}
+	n, err := e.w.Write(p)
+	if err == nil && e.rc != nil {
+		err = e.rc.Flush()
+	}
+	return n, err
+}
+
+// pairRelay waits for the other request of id, or returns nil once ctx is
+// done.
+func (s *Server) pairRelay(ctx context.Context, id string, e *relayEnd) *relayEnd {
+	s.relayMu.Lock()
+	ch, ok := s.relays[id]
+	if ok {
+		delete(s.relays, id)
+		s.relayMu.Unlock()
+		ch <- e
+		return <-ch
+	}
+	ch = make(chan *relayEnd)
+	s.relays[id] = ch
+	s.relayMu.Unlock()
+	select {
+	case peer := <-ch:
+		ch <- e
+		return peer
+	case <-ctx.Done():
+		s.relayMu.Lock()
+		defer s.relayMu.Unlock()
+		if s.relays[id] == ch {
+			delete(s.relays, id)
+			return nil
+		}
+		// The peer arrived meanwhile.
+		peer := <-ch
+		ch <- e
+		return peer
+	}
+}
+
+// relay pairs the two requests made for the session named by the path and
+// copies what each sends to the other, until either ends. A request is a
+// WebSocket, or a POST over HTTP/2 whose body and response are the two
+// directions; full-duplex HTTP/1.1 does not get through proxies. The peers
+// encrypt what they send, so the relay only sees ciphertext. App Engine
+// offers neither; run the signaling server elsewhere to use it.
+func (s *Server) relay(w http.ResponseWriter, r *http.Request) {
+	ws := isWebSocket(r)
+	if !ws && r.Method != http.MethodPost || r.URL.Path == "" {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if ws {
+		if err := checkWebSocket(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else if r.ProtoMajor < 2 {
+		w.Header().Set("Upgrade", "websocket")
+		http.Error(w, http.StatusText(http.StatusUpgradeRequired), http.StatusUpgradeRequired)
+		return
+	}
+	t, ok := s.tenant(w, r)
+	if !ok {
+		return
+	}
+	if !s.authorized(w, r, r.URL.Path) {
+		return
+	}
+	if t != nil {
+		s.mu.Lock()
+		u := s.usageOf(t)
+		over := t.Relays > 0 && u.relays >= 2*t.Relays
+		if !over {
+			u.relays++
+		}
+		s.mu.Unlock()
+		if over {
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+		defer func() {
+			s.mu.Lock()
+			s.usageOf(t).relays--
+			s.mu.Unlock()
+		}()
+	}
+	e := &relayEnd{ready: make(chan struct{}), done: make(chan struct{})}
+	ctx, cancel := context.WithTimeout(r.Context(), relayWait)
+	peer := s.pairRelay(ctx, t.mailbox(r.URL.Path), e)
+	cancel()
+	if peer == nil {
+		http.Error(w, ``, http.StatusGatewayTimeout)
+		return
+	}
+	s.relayMu.Lock()
+	s.relayActive++
+	s.relayMu.Unlock()
+	defer func() {
+		s.relayMu.Lock()
+		s.relayActive--
+		s.relayMu.Unlock()
+		e.mu.Lock()
+		e.closed = true
+		e.mu.Unlock()
+		close(e.done)
+	}()
+	var body io.Reader
+	if ws {
+		c, err := acceptWebSocket(w, r)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		e.w, body = c, c
+	} else {
+		rc := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		e.w, e.rc, body = w, rc, r.Body
+	}
+	close(e.ready)
+	select {
+	case <-peer.ready:
+	case <-peer.done:
+		return
+	case <-r.Context().Done():
+		return
+	}
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		io.Copy(peer, body)
+	}()
+	select {
+	case <-copied:
+	case <-peer.done:
+	}
+}
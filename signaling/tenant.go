@@ -0,0 +1,107 @@
+package signaling
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Tenants are the namespaces sharing a signaling server, read from a TOML
+// file of tables:
+//
+//	[tenant.team-a]
+//	token = "4b1f0c9e2d7a..." # API token of its clients
+//	mailboxes = 100           # mailboxes pulled at once
+//	rate = 600                # requests per minute
+//	relays = 10               # relayed sessions at once
+//
+// where a zero or missing quota is unlimited. Each tenant has mailboxes and
+// relays of its own: a key names a different mailbox in every tenant, so
+// none can reach or probe the keys of another. Clients send the token as a
+// bearer token, or as the password of basic authentication; requests
+// without a known token are answered 401 Unauthorized, and those over a
+// quota 429 Too Many Requests. The file is read again when it changes.
+type Tenants struct {
+	w *watched
+}
+
+// Tenant is a namespace of a signaling server; see Tenants.
+type Tenant struct {
+	Name      string `toml:"-"`
+	Token     string `toml:"token"`
+	Mailboxes int    `toml:"mailboxes"`
+	Rate      int    `toml:"rate"`
+	Relays    int    `toml:"relays"`
+}
+
+// LoadTenants reads the tenants of file, read again whenever it changes.
+func LoadTenants(file string) (*Tenants, error) {
+	t := &Tenants{w: &watched{files: []string{file}, load: func() (any, error) {
+		return loadTenants(file)
+	}}}
+	if _, err := t.w.get(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// minToken is the shortest token accepted, in bytes.
+const minToken = 16
+
+// loadTenants returns the tenants of file by the SHA-256 of their tokens,
+// so that looking a token up takes no time telling about the others.
+func loadTenants(file string) (map[[sha256.Size]byte]*Tenant, error) {
+	var f struct {
+		Tenant map[string]*Tenant `toml:"tenant"`
+	}
+	md, err := toml.DecodeFile(file, &f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	if keys := md.Undecoded(); len(keys) > 0 {
+		return nil, fmt.Errorf("%s: unknown field %q", file, keys[0].String())
+	}
+	tenants := map[[sha256.Size]byte]*Tenant{}
+	for name, t := range f.Tenant {
+		switch {
+		case strings.Contains(name, "/"):
+			return nil, fmt.Errorf("%s: tenant %q: name must not contain /", file, name)
+		case len(t.Token) < minToken:
+			return nil, fmt.Errorf("%s: tenant %q: token must be %d characters at least", file, name, minToken)
+		case t.Mailboxes < 0 || t.Rate < 0 || t.Relays < 0:
+			return nil, fmt.Errorf("%s: tenant %q: quotas must not be negative", file, name)
+		}
+		sum := sha256.Sum256([]byte(t.Token))
+		if other := tenants[sum]; other != nil {
+			return nil, fmt.Errorf("%s: tenants %q and %q share a token", file, other.Name, name)
+		}
+		t.Name = name
+		tenants[sum] = t
+	}
+	return tenants, nil
+}
+
+// lookup returns the tenant of the token of r, or nil.
+func (t *Tenants) lookup(r *http.Request) *Tenant {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, token, ok = r.BasicAuth()
+	}
+	if !ok {
+		return nil
+	}
+	v, _ := t.w.get()
+	return v.(map[[sha256.Size]byte]*Tenant)[sha256.Sum256([]byte(token))]
+}
+
+// mailbox returns the name under which the server keeps the mailbox id of
+// tenant t, id itself without tenants.
+func (t *Tenant) mailbox(id string) string {
+	if t == nil {
+		return id
+	}
+	return t.Name + "/" + id
+}
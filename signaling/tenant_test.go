@@ -0,0 +1,132 @@
+package signaling
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const tenantsFile = `
+[tenant.a]
+token = "aaaaaaaaaaaaaaaaaaaa"
+
+[tenant.b]
+token = "bbbbbbbbbbbbbbbbbbbb"
+mailboxes = 1
+rate = 4
+`
+
+func tenantServer(t *testing.T) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "tenants.toml")
+	writeFile(t, file, []byte(tenantsFile))
+	tenants, err := LoadTenants(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer()
+	s.Tenants = tenants
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func tenantClient(t *testing.T, base, token string) *Client {
+	t.Helper()
+	tr := Transport{Token: token}
+	hc, err := tr.HTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{Base: base, HTTPClient: hc, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	base := tenantServer(t)
+	a := tenantClient(t, base, "aaaaaaaaaaaaaaaaaaaa")
+	b := tenantClient(t, base, "bbbbbbbbbbbbbbbbbbbb")
+
+	var se *StatusError
+	if err := tenantClient(t, base, "").Push(context.Background(), "box", ConnectInfo{}); !errors.As(err, &se) || se.Code != http.StatusUnauthorized {
+		t.Fatalf("push without a token: %v", err)
+	}
+	if err := tenantClient(t, base, "cccccccccccccccccccc").Push(context.Background(), "box", ConnectInfo{}); !errors.As(err, &se) || se.Code != http.StatusUnauthorized {
+		t.Fatalf("push with an unknown token: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	msgs := a.Pull(ctx, slog.Default(), "box")
+	time.Sleep(100 * time.Millisecond)
+	// The same key of another tenant is another mailbox.
+	if err := b.Push(ctx, "box", ConnectInfo{Source: "b", SDP: "from b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Push(ctx, "box", ConnectInfo{Source: "a", SDP: "from a"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-msgs:
+		if v.SDP != "from a" || v.Destination != "box" {
+			t.Fatalf("got %+v", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message")
+	}
+}
+
+func TestTenantQuotas(t *testing.T) {
+	base := tenantServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := tenantClient(t, base, "bbbbbbbbbbbbbbbbbbbb")
+	get := func(path string) int {
+		req, _ := http.NewRequestWithContext(ctx, "GET", base+path, nil)
+		res, err := b.HTTPClient.Do(req)
+		if err != nil {
+			return 0
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	pulled := make(chan int, 1)
+	go func() { pulled <- get("/pull/one") }()
+	time.Sleep(100 * time.Millisecond)
+	if code := get("/pull/two"); code != http.StatusTooManyRequests {
+		t.Fatalf("second mailbox of a tenant limited to one: %d", code)
+	}
+	// The rate is 4 a minute: two requests more, then refused.
+	for i := 0; i < 2; i++ {
+		if err := b.Push(ctx, "one", ConnectInfo{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var se *StatusError
+	if err := b.Push(ctx, "one", ConnectInfo{}); !errors.As(err, &se) || se.Code != http.StatusTooManyRequests {
+		t.Fatalf("fifth request in a minute: %v", err)
+	}
+	cancel()
+	<-pulled
+}
+
+func TestTenantsInvalid(t *testing.T) {
+	for _, f := range []string{
+		"[tenant.a]\ntoken = \"short\"\n",
+		"[tenant.\"a/b\"]\ntoken = \"aaaaaaaaaaaaaaaaaaaa\"\n",
+		"[tenant.a]\ntoken = \"aaaaaaaaaaaaaaaaaaaa\"\n[tenant.b]\ntoken = \"aaaaaaaaaaaaaaaaaaaa\"\n",
+		"[tenant.a]\ntoken = \"aaaaaaaaaaaaaaaaaaaa\"\nrelays = -1\n",
+		"[tenant.a]\ntoken = \"aaaaaaaaaaaaaaaaaaaa\"\nquota = 1\n",
+	} {
+		file := filepath.Join(t.TempDir(), "tenants.toml")
+		writeFile(t, file, []byte(f))
+		if _, err := LoadTenants(file); err == nil {
+			t.Errorf("%q accepted", f)
+		}
+	}
+}
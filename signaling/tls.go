@@ -0,0 +1,227 @@
+package signaling
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerTLS tells how a signaling server serves TLS: with which
+// certificate and, for mutual TLS, which client certificates it accepts.
+type ServerTLS struct {
+	// CertFile and KeyFile are the PEM certificate chain and private key
+	// of the server.
+	CertFile string
+	KeyFile  string
+	// ClientCAFiles, if set, are PEM bundles of the authorities issuing
+	// client certificates; clients without one are refused.
+	ClientCAFiles []string
+}
+
+// Config returns a configuration for t. Its files are loaded again once
+// any of them changes, so a rotated certificate is served without a
+// restart; a rotation failing to load is logged and the previous files
+// stay in use.
+func (t *ServerTLS) Config() (*tls.Config, error) {
+	if t.CertFile == "" || t.KeyFile == "" {
+		return nil, errors.New("a server certificate needs both its certificate and key files")
+	}
+	w := &watched{files: append([]string{t.CertFile, t.KeyFile}, t.ClientCAFiles...), load: func() (any, error) {
+		return t.load()
+	}}
+	if _, err := w.get(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			v, err := w.get()
+			if err != nil {
+				return nil, err
+			}
+			return v.(*tls.Config), nil
+		},
+	}, nil
+}
+
+func (t *ServerTLS) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	conf := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	if len(t.ClientCAFiles) > 0 {
+		pool := x509.NewCertPool()
+		for _, f := range t.ClientCAFiles {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			if !pool.AppendCertsFromPEM(b) {
+				return nil, fmt.Errorf("%s: no PEM certificate", f)
+			}
+		}
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+		conf.ClientCAs = pool
+	}
+	return conf, nil
+}
+
+// watched is a value loaded from files, loaded again when their
+// modification times change.
+type watched struct {
+	files []string
+	load  func() (any, error)
+
+	mu  sync.Mutex
+	mod []time.Time
+	v   any
+}
+
+// get returns the value of the files as they are now, or the last one
+// loaded if they fail to load again.
+func (w *watched) get() (any, error) {
+	mod := make([]time.Time, len(w.files))
+	for i, f := range w.files {
+		if fi, err := os.Stat(f); err == nil {
+			mod[i] = fi.ModTime()
+		}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.v != nil && equalTimes(mod, w.mod) {
+		return w.v, nil
+	}
+	v, err := w.load()
+	if err != nil {
+		if w.v == nil {
+			return nil, err
+		}
+		log.Print("reload failed, keeping the previous files: ", err)
+		// Retry once the files change again.
+		w.mod = mod
+		return w.v, nil
+	}
+	if w.v != nil {
+		log.Printf("reloaded %s", strings.Join(w.files, ", "))
+	}
+	w.mod, w.v = mod, v
+	return v, nil
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ACL maps the identities of client certificates to the mailboxes they
+// may use. Its file holds lines of
+//
+//	identity mailbox...
+//
+// where the identity is the common name or a DNS, email or URI name of a
+// certificate, and a mailbox is the id of a key, limits left out, or * for
+// any. A mailbox named on some line may only be used by the identities
+// naming it; the others, the session mailboxes among them, by every
+// client. Blank lines and lines starting with # are skipped.
+type ACL struct {
+	w *watched
+}
+
+// aclRules is a parsed ACL file.
+type aclRules struct {
+	allow   map[string]map[string]bool // identity to mailboxes
+	claimed map[string]bool            // mailboxes named by some line
+}
+
+// LoadACL reads the ACL of file, read again whenever it changes.
+func LoadACL(file string) (*ACL, error) {
+	a := &ACL{w: &watched{files: []string{file}, load: func() (any, error) {
+		return loadACL(file)
+	}}}
+	if _, err := a.w.get(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func loadACL(file string) (*aclRules, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rules := &aclRules{allow: map[string]map[string]bool{}, claimed: map[string]bool{}}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: identity without mailboxes", file, n)
+		}
+		id := fields[0]
+		if rules.allow[id] == nil {
+			rules.allow[id] = map[string]bool{}
+		}
+		for _, m := range fields[1:] {
+			rules.allow[id][m] = true
+			if m != "*" {
+				rules.claimed[m] = true
+			}
+		}
+	}
+	return rules, sc.Err()
+}
+
+// Authorize tells whether the verified client certificate of r may use
+// mailbox; it suits Server.Authorize.
+func (a *ACL) Authorize(r *http.Request, mailbox string) bool {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return false
+	}
+	v, _ := a.w.get()
+	rules := v.(*aclRules)
+	if k, err := ParseKey(mailbox); err == nil {
+		mailbox = k.ID
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	for _, id := range identities(cert) {
+		if allow := rules.allow[id]; allow[mailbox] || allow["*"] {
+			return true
+		}
+	}
+	return !rules.claimed[mailbox]
+}
+
+// identities returns the names of cert an ACL may refer to.
+func identities(cert *x509.Certificate) []string {
+	var ids []string
+	if cert.Subject.CommonName != "" {
+		ids = append(ids, cert.Subject.CommonName)
+	}
+	ids = append(ids, cert.DNSNames...)
+	ids = append(ids, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		ids = append(ids, u.String())
+	}
+	return ids
+}
@@ -0,0 +1,173 @@
+package signaling
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// serveTLS serves s with conf and returns its base URL.
+func serveTLS(t *testing.T, s *Server, conf ServerTLS) string {
+	t.Helper()
+	tc, err := conf.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: s, TLSConfig: tc}
+	go srv.ServeTLS(ln, "", "")
+	t.Cleanup(func() { srv.Close() })
+	return "https://" + ln.Addr().String()
+}
+
+func writeFile(t *testing.T, file string, b []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// touch makes sure the files count as changed, whatever the resolution of
+// their modification times.
+func touch(t *testing.T, files ...string) {
+	t.Helper()
+	later := time.Now().Add(time.Minute)
+	for _, f := range files {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestServerTLSReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := writeCert(t, dir, "server")
+	old := filepath.Join(dir, "old.pem")
+	b, err := os.ReadFile(certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, old, b)
+	base := serveTLS(t, NewServer(), ServerTLS{CertFile: certFile, KeyFile: keyFile})
+	if err := push(t, base, Transport{CAFiles: []string{old}}); err != nil {
+		t.Fatal(err)
+	}
+
+	writeCert(t, dir, "server")
+	touch(t, certFile, keyFile)
+	if err := push(t, base, Transport{CAFiles: []string{old}}); err == nil {
+		t.Fatal("the rotated certificate is not served")
+	}
+	if err := push(t, base, Transport{CAFiles: []string{certFile}}); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, keyFile, []byte("half written"))
+	touch(t, keyFile)
+	if err := push(t, base, Transport{CAFiles: []string{certFile}}); err != nil {
+		t.Fatalf("a broken rotation replaced the certificate: %v", err)
+	}
+}
+
+func TestACL(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := writeCert(t, dir, "server")
+	aliceCert, aliceKey, _ := writeCert(t, dir, "alice")
+	bobCert, bobKey, _ := writeCert(t, dir, "bob")
+	file := filepath.Join(dir, "acl")
+	writeFile(t, file, []byte("# identity mailbox...\nalice key1 key2\n\nadmin *\n"))
+	acl, err := LoadACL(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer()
+	s.Authorize = acl.Authorize
+	base := serveTLS(t, s, ServerTLS{CertFile: certFile, KeyFile: keyFile, ClientCAFiles: []string{aliceCert, bobCert}})
+	alice := Transport{CAFiles: []string{certFile}, CertFile: aliceCert, KeyFile: aliceKey}
+	bob := Transport{CAFiles: []string{certFile}, CertFile: bobCert, KeyFile: bobKey}
+
+	for _, tc := range []struct {
+		name    string
+		tr      Transport
+		mailbox string
+		ok      bool
+	}{
+		{"own key", alice, "key1.u3", true},
+		{"key of another", bob, "key1", false},
+		{"session", bob, "7b1e2c3a", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := pushTo(t, base, tc.tr, tc.mailbox)
+			var se *StatusError
+			switch {
+			case tc.ok && err != nil:
+				t.Fatal(err)
+			case !tc.ok && (!errors.As(err, &se) || se.Code != http.StatusForbidden):
+				t.Fatalf("got %v, want 403", err)
+			}
+		})
+	}
+
+	writeFile(t, file, []byte("alice key1\nbob key1\n"))
+	touch(t, file)
+	if err := pushTo(t, base, bob, "key1"); err != nil {
+		t.Fatalf("after granting key1: %v", err)
+	}
+	if _, err := LoadACL(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("missing ACL loaded")
+	}
+}
+
+func TestRelayHTTP2(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := writeCert(t, dir, "server")
+	base := serveTLS(t, NewServer(), ServerTLS{CertFile: certFile, KeyFile: keyFile})
+	tr := Transport{CAFiles: []string{certFile}}
+	hc, err := tr.HTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	type end struct {
+		w   *io.PipeWriter
+		res chan *http.Response
+	}
+	open := func() end {
+		pr, pw := io.Pipe()
+		e := end{pw, make(chan *http.Response, 1)}
+		go func() {
+			res, err := hc.Post(base+"/relay/session", "application/octet-stream", pr)
+			if err != nil {
+				t.Error(err)
+				close(e.res)
+				return
+			}
+			e.res <- res
+		}()
+		return e
+	}
+	a, b := open(), open()
+	ra, rb := <-a.res, <-b.res
+	if ra == nil || rb == nil {
+		t.FailNow()
+	}
+	defer ra.Body.Close()
+	defer rb.Body.Close()
+	if ra.ProtoMajor != 2 || ra.StatusCode != http.StatusOK {
+		t.Fatalf("relay answered %s over %s", ra.Status, ra.Proto)
+	}
+	go a.w.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(rb.Body, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("relayed %q: %v", buf, err)
+	}
+	a.w.Close()
+	b.w.Close()
+}
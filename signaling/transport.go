@@ -0,0 +1,132 @@
+package signaling
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Transport tells how to reach a signaling server: through which proxy,
+// trusting which certificates and presenting which. The zero value uses
+// the proxy of the environment and the system roots, as
+// http.DefaultClient does.
+type Transport struct {
+	// Proxy is the URL of an HTTP proxy, with user:password@ for basic
+	// authentication, instead of HTTPS_PROXY and HTTP_PROXY.
+	Proxy string
+	// CAFiles are PEM bundles trusted besides the system roots.
+	CAFiles []string
+	// CertFile and KeyFile are the PEM certificate chain and private key
+	// presented to a server asking for a client certificate.
+	CertFile string
+	KeyFile  string
+	// Pins restricts the servers to those whose verified certificate
+	// chain, root included, holds the public key of one of the pins; see
+	// Pin.
+	Pins []string
+	// Token is the API token of a tenant of the server, sent as a bearer
+	// token; see Tenants.
+	Token string
+}
+
+// Pin returns the pin of a certificate's public key: "sha256:" and the
+// unpadded base64url SHA-256 of its DER SubjectPublicKeyInfo.
+func Pin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256:" + base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// HTTPClient returns a client for t, with its files loaded, or
+// http.DefaultClient for the zero value.
+func (t *Transport) HTTPClient() (*http.Client, error) {
+	if t.Proxy == "" && len(t.CAFiles) == 0 && t.CertFile == "" && t.KeyFile == "" && len(t.Pins) == 0 && t.Token == "" {
+		return http.DefaultClient, nil
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if t.Proxy != "" {
+		u, err := url.Parse(t.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("proxy: %v", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" || u.Host == "" {
+			return nil, fmt.Errorf("proxy: unsupported url %q", u.Redacted())
+		}
+		tr.Proxy = http.ProxyURL(u)
+	}
+	conf := &tls.Config{}
+	if len(t.CAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, f := range t.CAFiles {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			if !pool.AppendCertsFromPEM(b) {
+				return nil, fmt.Errorf("%s: no PEM certificate", f)
+			}
+		}
+		conf.RootCAs = pool
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		if t.CertFile == "" || t.KeyFile == "" {
+			return nil, errors.New("a client certificate needs both its certificate and key files")
+		}
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	if len(t.Pins) > 0 {
+		pins := map[string]bool{}
+		for _, p := range t.Pins {
+			b, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(p, "sha256:"))
+			if !strings.HasPrefix(p, "sha256:") || err != nil || len(b) != sha256.Size {
+				return nil, fmt.Errorf("invalid pin %q, expected sha256: and 43 base64url characters", p)
+			}
+			pins[p] = true
+		}
+		// The chain was verified first: a pin narrows the trusted
+		// certificates, it does not replace them.
+		conf.VerifyConnection = func(cs tls.ConnectionState) error {
+			for _, chain := range cs.VerifiedChains {
+				for _, cert := range chain {
+					if pins[Pin(cert)] {
+						return nil
+					}
+				}
+			}
+			return fmt.Errorf("signaling: certificate of %s matches no pin", cs.ServerName)
+		}
+	}
+	tr.TLSClientConfig = conf
+	if t.Token != "" {
+		return &http.Client{Transport: &tokenTransport{token: t.Token, base: tr}}, nil
+	}
+	return &http.Client{Transport: tr}, nil
+}
+
+// tokenTransport adds a bearer token to the requests without
+// authorization.
+type tokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,161 @@
+package signaling
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeCert writes a new self-signed certificate and its key to dir and
+// returns their files and the certificate.
+func writeCert(t *testing.T, dir, name string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv6loopback, net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+	kder, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", kder)
+	return certFile, keyFile, cert
+}
+
+func writePEM(t *testing.T, file, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func push(t *testing.T, base string, tr Transport) error {
+	t.Helper()
+	return pushTo(t, base, tr, "box")
+}
+
+func pushTo(t *testing.T, base string, tr Transport, mailbox string) error {
+	t.Helper()
+	hc, err := tr.HTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{Base: base, HTTPClient: hc, MinBackoff: time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.Push(ctx, mailbox, ConnectInfo{})
+}
+
+func TestTransportTLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, clientKey, cert := writeCert(t, dir, "client")
+	clients := x509.NewCertPool()
+	clients.AddCert(cert)
+
+	ts := httptest.NewUnstartedServer(NewServer())
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clients}
+	ts.StartTLS()
+	defer ts.Close()
+	ca := filepath.Join(dir, "ca.pem")
+	writePEM(t, ca, "CERTIFICATE", ts.Certificate().Raw)
+	other := Pin(cert)
+
+	for _, tc := range []struct {
+		name string
+		tr   Transport
+		ok   bool
+	}{
+		{"untrusted", Transport{CertFile: clientCert, KeyFile: clientKey}, false},
+		{"no client certificate", Transport{CAFiles: []string{ca}}, false},
+		{"mutual", Transport{CAFiles: []string{ca}, CertFile: clientCert, KeyFile: clientKey}, true},
+		{"pinned", Transport{CAFiles: []string{ca}, CertFile: clientCert, KeyFile: clientKey, Pins: []string{other, Pin(ts.Certificate())}}, true},
+		{"wrong pin", Transport{CAFiles: []string{ca}, CertFile: clientCert, KeyFile: clientKey, Pins: []string{other}}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := push(t, ts.URL, tc.tr); (err == nil) != tc.ok {
+				t.Fatalf("push: %v", err)
+			}
+		})
+	}
+}
+
+func TestTransportProxy(t *testing.T) {
+	ts := httptest.NewServer(NewServer())
+	defer ts.Close()
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != auth {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		proxied.Add(1)
+		r.RequestURI = ""
+		r.Header.Del("Proxy-Authorization")
+		res, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer res.Body.Close()
+		w.WriteHeader(res.StatusCode)
+		io.Copy(w, res.Body)
+	}))
+	defer proxy.Close()
+
+	if err := push(t, ts.URL, Transport{Proxy: "http://user:secret@" + proxy.Listener.Addr().String()}); err != nil {
+		t.Fatal(err)
+	}
+	if proxied.Load() != 1 {
+		t.Fatalf("%d requests through the proxy", proxied.Load())
+	}
+	if err := push(t, ts.URL, Transport{Proxy: "http://user:wrong@" + proxy.Listener.Addr().String()}); err == nil {
+		t.Fatal("push through a proxy refusing the credentials")
+	}
+}
+
+func TestTransportInvalid(t *testing.T) {
+	for _, tr := range []Transport{
+		{Proxy: "ftp://proxy"},
+		{CAFiles: []string{"/nonexistent"}},
+		{CertFile: "cert.pem"},
+		{Pins: []string{"sha256:short"}},
+	} {
+		if _, err := tr.HTTPClient(); err == nil {
+			t.Errorf("%+v accepted", tr)
+		}
+	}
+}
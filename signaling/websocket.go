@@ -0,0 +1,323 @@
+package signaling
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Over HTTP/1.1 the relay runs in a WebSocket (RFC 6455), which proxies
+// forward as they do any upgraded connection, and carries the stream in
+// binary messages. Only what the relay needs is implemented: no
+// extensions, subprotocols or text messages.
+
+const (
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// maxWSFrame bounds the frames read.
+	maxWSFrame = 1 << 20
+	// wsCloseWait bounds the wait of Close for a write in progress.
+	wsCloseWait = 5 * time.Second
+)
+
+// Frame opcodes.
+const (
+	wsContinuation = 0x0
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+var errWSFrame = errors.New("websocket: malformed frame")
+
+func wsAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// hasToken reports whether a comma-separated header of h lists token.
+func hasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isWebSocket reports whether r asks to switch to a WebSocket.
+func isWebSocket(r *http.Request) bool {
+	return r.Method == http.MethodGet && hasToken(r.Header, "Connection", "upgrade") && hasToken(r.Header, "Upgrade", "websocket")
+}
+
+// checkWebSocket verifies the opening handshake of r, which isWebSocket.
+func checkWebSocket(r *http.Request) error {
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return errors.New("websocket: unsupported version")
+	}
+	if b, err := base64.StdEncoding.DecodeString(r.Header.Get("Sec-WebSocket-Key")); err != nil || len(b) != 16 {
+		return errors.New("websocket: malformed key")
+	}
+	return nil
+}
+
+// acceptWebSocket switches the connection of r, which passed
+// checkWebSocket, to the WebSocket protocol.
+func acceptWebSocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// The server's timeouts are for requests, not for the relayed stream.
+	conn.SetDeadline(time.Time{})
+	fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", wsAccept(r.Header.Get("Sec-WebSocket-Key")))
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return newWSConn(brw.Reader, conn, conn, false), nil
+}
+
+// DialWebSocket opens a WebSocket to url, an http or https URL, through
+// client and returns the byte stream its binary messages carry. ctx only
+// bounds the opening handshake.
+func DialWebSocket(ctx context.Context, client *http.Client, url string) (io.ReadWriteCloser, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(b)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	rwc, ok := res.Body.(io.ReadWriteCloser)
+	if res.StatusCode != http.StatusSwitchingProtocols || !ok {
+		res.Body.Close()
+		return nil, fmt.Errorf("websocket: %s", res.Status)
+	}
+	if res.Header.Get("Sec-WebSocket-Accept") != wsAccept(key) {
+		rwc.Close()
+		return nil, errors.New("websocket: handshake not accepted")
+	}
+	return newWSConn(rwc, rwc, rwc, true), nil
+}
+
+// wsConn is the byte stream carried by the binary messages of a
+// WebSocket. A write sends one frame.
+type wsConn struct {
+	w      io.Writer
+	closer io.Closer
+	client bool // mask the frames written, as clients must
+
+	rmu    sync.Mutex
+	r      *bufio.Reader
+	remain uint64 // left to read of the current frame
+	mask   [4]byte
+	masked bool
+	pos    int // in mask
+
+	wmu    sync.Mutex
+	closed bool // the close frame was sent
+}
+
+func newWSConn(r io.Reader, w io.Writer, c io.Closer, client bool) *wsConn {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &wsConn{r: br, w: w, closer: c, client: client}
+}
+
+// Read returns the payload of binary messages, answering pings, and
+// io.EOF once the peer closed the WebSocket.
+func (c *wsConn) Read(p []byte) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+	for c.remain == 0 {
+		op, err := c.readHeader()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case wsBinary, wsContinuation:
+		case wsClose, wsPing, wsPong:
+			payload := make([]byte, c.remain)
+			for i := 0; i < len(payload); {
+				n, err := c.readPayload(payload[i:])
+				if err != nil {
+					return 0, err
+				}
+				i += n
+			}
+			switch op {
+			case wsClose:
+				c.writeClose()
+				return 0, io.EOF
+			case wsPing:
+				c.writeFrame(wsPong, payload)
+			}
+		default:
+			return 0, errWSFrame
+		}
+	}
+	if uint64(len(p)) > c.remain {
+		p = p[:c.remain]
+	}
+	n, err := c.readPayload(p)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// readHeader reads the header of the next frame and returns its opcode.
+func (c *wsConn) readHeader() (byte, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.r, hdr[:]); err != nil {
+		return 0, err
+	}
+	op := hdr[0] & 0x0f
+	c.masked = hdr[1]&0x80 != 0
+	if c.masked == c.client || hdr[0]&0x70 != 0 {
+		// Clients mask their frames and servers do not; no extension
+		// uses the reserved bits.
+		return 0, errWSFrame
+	}
+	n := uint64(hdr[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.r, b[:]); err != nil {
+			return 0, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.r, b[:]); err != nil {
+			return 0, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	if n > maxWSFrame || op >= wsClose && n > 125 {
+		return 0, errWSFrame
+	}
+	if c.masked {
+		if _, err := io.ReadFull(c.r, c.mask[:]); err != nil {
+			return 0, err
+		}
+	}
+	c.remain, c.pos = n, 0
+	return op, nil
+}
+
+// readPayload reads up to len(p) bytes of the current frame, at most
+// what is left of it.
+func (c *wsConn) readPayload(p []byte) (int, error) {
+	n, err := io.ReadAtLeast(c.r, p, min(len(p), 1))
+	if c.masked {
+		for i := range p[:n] {
+			p[i] ^= c.mask[(c.pos+i)%4]
+		}
+		c.pos = (c.pos + n) % 4
+	}
+	c.remain -= uint64(n)
+	return n, err
+}
+
+// Write sends p in a binary message.
+func (c *wsConn) Write(p []byte) (int, error) {
+	if err := c.writeFrame(wsBinary, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *wsConn) writeFrame(op byte, p []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closed {
+		return io.ErrClosedPipe
+	}
+	return c.writeFrameLocked(op, p)
+}
+
+func (c *wsConn) writeFrameLocked(op byte, p []byte) error {
+	frame := make([]byte, 0, 14+len(p))
+	frame = append(frame, 0x80|op)
+	var mbit byte
+	if c.client {
+		mbit = 0x80
+	}
+	switch n := len(p); {
+	case n < 126:
+		frame = append(frame, mbit|byte(n))
+	case n <= 0xffff:
+		frame = binary.BigEndian.AppendUint16(append(frame, mbit|126), uint16(n))
+	default:
+		frame = binary.BigEndian.AppendUint64(append(frame, mbit|127), uint64(n))
+	}
+	if !c.client {
+		_, err := c.w.Write(append(frame, p...))
+		return err
+	}
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range p {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := c.w.Write(frame)
+	return err
+}
+
+// writeClose sends the close frame, once.
+func (c *wsConn) writeClose() {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if !c.closed {
+		c.closed = true
+		c.writeFrameLocked(wsClose, binary.BigEndian.AppendUint16(nil, 1000))
+	}
+}
+
+// Close sends the close frame and closes the connection without waiting
+// for the peer's.
+func (c *wsConn) Close() error {
+	sent := make(chan struct{})
+	go func() {
+		c.writeClose()
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(wsCloseWait):
+		// Closing the connection fails the write in progress.
+	}
+	return c.closer.Close()
+}
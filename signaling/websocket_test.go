@@ -0,0 +1,92 @@
+package signaling
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRelayWebSocket(t *testing.T) {
+	ts := httptest.NewServer(NewServer())
+	defer ts.Close()
+	// The relay gets through a proxy forwarding upgraded connections.
+	u, _ := url.Parse(ts.URL)
+	proxy := httptest.NewServer(httputil.NewSingleHostReverseProxy(u))
+	defer proxy.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	type result struct {
+		ws  io.ReadWriteCloser
+		err error
+	}
+	open := func() chan result {
+		ch := make(chan result, 1)
+		go func() {
+			ws, err := DialWebSocket(ctx, nil, proxy.URL+"/relay/session")
+			ch <- result{ws, err}
+		}()
+		return ch
+	}
+	ca, cb := open(), open()
+	ra, rb := <-ca, <-cb
+	if ra.err != nil || rb.err != nil {
+		t.Fatalf("dial: %v, %v", ra.err, rb.err)
+	}
+	a, b := ra.ws, rb.ws
+	defer b.Close()
+
+	if _, err := a.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(b, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("relayed %q: %v", buf, err)
+	}
+	// Large enough for 64-bit frame lengths.
+	big := bytes.Repeat([]byte("0123456789"), 10000)
+	go b.Write(big)
+	got := make([]byte, len(big))
+	if _, err := io.ReadFull(a, got); err != nil || !bytes.Equal(got, big) {
+		t.Fatalf("relayed %d bytes: %v", len(got), err)
+	}
+
+	a.Close()
+	if n, err := b.Read(buf); err != io.EOF {
+		t.Fatalf("read after the peer closed: %d, %v", n, err)
+	}
+}
+
+func TestRelayHTTP1(t *testing.T) {
+	ts := httptest.NewServer(NewServer())
+	defer ts.Close()
+	res, err := http.Post(ts.URL+"/relay/session", "application/octet-stream", strings.NewReader("ping"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUpgradeRequired {
+		t.Fatalf("full-duplex HTTP/1.1 relay answered %s", res.Status)
+	}
+
+	req, _ := http.NewRequest("GET", ts.URL+"/relay/session", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "short")
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("malformed handshake answered %s", res.Status)
+	}
+}
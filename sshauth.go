@@ -0,0 +1,317 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// A server rule with authorized_keys only dials its target once the client
+// peer signed the session's DTLS fingerprints with an SSH key listed there.
+// The server verifies the client's DTLS certificate, so a signature cannot
+// be replayed in another session.
+
+var (
+	errNotAuthorized = errors.New("no authorized key")
+	errNotPermitted  = errors.New("target not permitted for key")
+	errNotFrom       = errors.New("client address not allowed for key")
+	errAuthExpired   = errors.New("authorized key expired")
+)
+
+// authData returns the data signed by the client peer.
+func authData(offerFP, answerFP string) []byte {
+	return []byte("ssh-p2p auth\n" + offerFP + "\n" + answerFP)
+}
+
+// signers returns the keys named by spec, which is "agent" for the keys
+// of ssh-agent or the path of a private key file. The agent connection, if
+// any, must be closed by the caller once signing is done.
+func signers(spec string) ([]ssh.Signer, func(), error) {
+	if spec == "agent" {
+		sock := os.Getenv("SSH_AUTH_SOCK")
+		if sock == "" {
+			return nil, nil, errors.New("SSH_AUTH_SOCK is not set")
+		}
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			return nil, nil, err
+		}
+		ss, err := agent.NewClient(conn).Signers()
+		if err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		return ss, func() { conn.Close() }, nil
+	}
+	b, err := os.ReadFile(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v (use ssh-agent for encrypted keys)", spec, err)
+	}
+	return []ssh.Signer{signer}, func() {}, nil
+}
+
+// signAuth returns the arguments of an auth notice: a public key and a
+// signature of data for each key named by spec.
+func signAuth(spec string, data []byte) (string, error) {
+	ss, done, err := signers(spec)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+	var args []string
+	for _, signer := range ss {
+		sig, err := signer.Sign(rand.Reader, data)
+		if err != nil {
+			return "", err
+		}
+		args = append(args,
+			base64.RawURLEncoding.EncodeToString(signer.PublicKey().Marshal()),
+			base64.RawURLEncoding.EncodeToString(ssh.Marshal(sig)))
+	}
+	if len(args) == 0 {
+		return "", errors.New("no ssh keys")
+	}
+	return strings.Join(args, " "), nil
+}
+
+// authorizedKey is an entry of an authorized_keys file.
+type authorizedKey struct {
+	key       ssh.PublicKey
+	comment   string
+	permit    []string  // permitopen targets, none means any
+	from      []string  // from patterns, none means any client
+	expires   time.Time // expiry-time, zero for none
+	noForward bool      // restrict or no-port-forwarding
+}
+
+// Options of an entry that only concern the SSH session itself, which the
+// target enforces if it reads the same file, and not the tunnel.
+var sessionOptions = map[string]bool{
+	"agent-forwarding": true, "no-agent-forwarding": true,
+	"pty": true, "no-pty": true,
+	"user-rc": true, "no-user-rc": true,
+	"x11-forwarding": true, "no-x11-forwarding": true,
+	"command": true, "environment": true, "permitlisten": true,
+	"no-touch-required": true, "verify-required": true,
+}
+
+// loadAuthorizedKeys reads an authorized_keys file. As the tunnel is a
+// port forward, restrict and no-port-forwarding deny it unless
+// port-forwarding follows, permitopen restricts its targets, from the
+// client addresses and expiry-time its lifetime. An option that neither
+// these nor sessionOptions cover fails the file rather than being ignored.
+func loadAuthorizedKeys(path string) ([]authorizedKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []authorizedKey
+	for len(b) > 0 {
+		key, comment, options, rest, err := ssh.ParseAuthorizedKey(b)
+		if err != nil {
+			// ParseAuthorizedKey skips bad lines and only fails once
+			// no valid key is left.
+			break
+		}
+		ak := authorizedKey{key: key, comment: comment}
+		for _, o := range options {
+			if err := ak.setOption(o); err != nil {
+				return nil, fmt.Errorf("%s: key %s: %v", path, ssh.FingerprintSHA256(key), err)
+			}
+		}
+		keys = append(keys, ak)
+		b = rest
+	}
+	return keys, nil
+}
+
+// setOption applies an option of the entry, in the order of the file.
+func (ak *authorizedKey) setOption(o string) error {
+	name, v, _ := strings.Cut(o, "=")
+	v = strings.Trim(v, `"`)
+	switch name = strings.ToLower(name); name {
+	case "restrict", "no-port-forwarding":
+		ak.noForward = true
+	case "port-forwarding":
+		ak.noForward = false
+	case "permitopen":
+		ak.permit = append(ak.permit, v)
+	case "from":
+		ak.from = append(ak.from, strings.Split(v, ",")...)
+	case "expiry-time":
+		t, err := parseExpiryTime(v)
+		if err != nil {
+			return err
+		}
+		ak.expires = t
+	default:
+		if !sessionOptions[name] {
+			return fmt.Errorf("unsupported option %q", name)
+		}
+	}
+	return nil
+}
+
+// parseExpiryTime parses the YYYYMMDD[HHMM[SS]] time of expiry-time, in
+// local time unless it ends in Z.
+func parseExpiryTime(v string) (time.Time, error) {
+	loc := time.Local
+	if s, ok := strings.CutSuffix(v, "Z"); ok {
+		v, loc = s, time.UTC
+	}
+	for _, layout := range []string{"20060102", "200601021504", "20060102150405"} {
+		if len(v) == len(layout) {
+			if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid expiry-time %q", v)
+}
+
+func (ak authorizedKey) fingerprint() string {
+	return ssh.FingerprintSHA256(ak.key)
+}
+
+// permits reports whether the entry allows dialing addr. As in OpenSSH a
+// port of "*" matches any port.
+func (ak authorizedKey) permits(addr string) bool {
+	if ak.noForward {
+		return false
+	}
+	if len(ak.permit) == 0 {
+		return true
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	for _, p := range ak.permit {
+		h, pt, err := net.SplitHostPort(p)
+		if err != nil {
+			continue
+		}
+		if h == host && (pt == "*" || pt == port) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether the entry accepts a client at ip, nil when the
+// client's address is unknown. As in OpenSSH, a pattern is an address, a
+// CIDR block or a wildcard pattern, and a negated match denies.
+func (ak authorizedKey) allows(ip net.IP) bool {
+	if len(ak.from) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	allowed := false
+	for _, p := range ak.from {
+		neg := strings.HasPrefix(p, "!")
+		p = strings.TrimPrefix(p, "!")
+		var match bool
+		if _, n, err := net.ParseCIDR(p); err == nil {
+			match = n.Contains(ip)
+		} else {
+			match = wildcardMatch(p, ip.String())
+		}
+		if match && neg {
+			return false
+		}
+		allowed = allowed || match
+	}
+	return allowed
+}
+
+// wildcardMatch matches s against pattern, where * matches any run of
+// characters and ? any one.
+func wildcardMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if wildcardMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return s == ""
+}
+
+// checkAuth verifies the arguments of an auth notice against the keys of
+// the authorized_keys file at path and returns the first key that signed
+// data and lets the client at ip, nil if unknown, reach addr.
+func checkAuth(path, addr string, ip net.IP, args string, data []byte) (*authorizedKey, error) {
+	keys, err := loadAuthorizedKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(args)
+	result := errNotAuthorized
+	for i := 0; i+1 < len(fields); i += 2 {
+		pb, err := base64.RawURLEncoding.DecodeString(fields[i])
+		if err != nil {
+			continue
+		}
+		sb, err := base64.RawURLEncoding.DecodeString(fields[i+1])
+		if err != nil {
+			continue
+		}
+		pub, err := ssh.ParsePublicKey(pb)
+		if err != nil {
+			continue
+		}
+		var sig ssh.Signature
+		if err := ssh.Unmarshal(sb, &sig); err != nil {
+			continue
+		}
+		for _, ak := range keys {
+			if string(ak.key.Marshal()) != string(pb) {
+				continue
+			}
+			if err := pub.Verify(data, &sig); err != nil {
+				break
+			}
+			if !ak.expires.IsZero() && time.Now().After(ak.expires) {
+				result = errAuthExpired
+				break
+			}
+			if !ak.allows(ip) {
+				result = errNotFrom
+				break
+			}
+			if !ak.permits(addr) {
+				result = errNotPermitted
+				break
+			}
+			return &ak, nil
+		}
+	}
+	return nil, result
+}
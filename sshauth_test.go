@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// authArgs returns a new SSH key and the arguments of an auth notice it
+// signs data with.
+func authArgs(t *testing.T, data []byte) (ssh.PublicKey, string) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.Sign(rand.Reader, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := signer.PublicKey()
+	return pub, base64.RawURLEncoding.EncodeToString(pub.Marshal()) + " " + base64.RawURLEncoding.EncodeToString(ssh.Marshal(sig))
+}
+
+// writeAuthorizedKeys writes an authorized_keys file listing pub with
+// options.
+func writeAuthorizedKeys(t *testing.T, options string, pub ssh.PublicKey) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "authorized_keys")
+	line := strings.TrimSpace(options + " " + string(ssh.MarshalAuthorizedKey(pub)))
+	if err := os.WriteFile(path, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAuthorizedKeyOptions(t *testing.T) {
+	data := authData("offer", "answer")
+	pub, args := authArgs(t, data)
+	client := net.ParseIP("192.0.2.7")
+	for _, tc := range []struct {
+		options string
+		ip      net.IP
+		want    error
+	}{
+		{"", nil, nil},
+		{"no-pty,command=\"echo\"", client, nil},
+		{"restrict", client, errNotPermitted},
+		{"no-port-forwarding", client, errNotPermitted},
+		{"restrict,port-forwarding,permitopen=\"127.0.0.1:22\"", client, nil},
+		{"permitopen=\"127.0.0.1:*\"", client, nil},
+		{"permitopen=\"127.0.0.1:2222\"", client, errNotPermitted},
+		{"from=\"192.0.2.0/24\"", client, nil},
+		{"from=\"192.0.2.*\"", client, nil},
+		{"from=\"192.0.2.0/24,!192.0.2.7\"", client, errNotFrom},
+		{"from=\"198.51.100.1\"", client, errNotFrom},
+		// Over a relay the client's address is unknown.
+		{"from=\"192.0.2.0/24\"", nil, errNotFrom},
+		{"expiry-time=\"20990101\"", client, nil},
+		{"expiry-time=\"200001010000Z\"", client, errAuthExpired},
+	} {
+		path := writeAuthorizedKeys(t, tc.options, pub)
+		_, err := checkAuth(path, "127.0.0.1:22", tc.ip, args, data)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s from %v: %v, want %v", tc.options, tc.ip, err, tc.want)
+		}
+	}
+
+	other, _ := authArgs(t, data)
+	if _, err := checkAuth(writeAuthorizedKeys(t, "", other), "127.0.0.1:22", client, args, data); !errors.Is(err, errNotAuthorized) {
+		t.Errorf("unlisted key: %v, want %v", err, errNotAuthorized)
+	}
+}
+
+func TestAuthorizedKeyUnsupported(t *testing.T) {
+	pub, _ := authArgs(t, nil)
+	for _, options := range []string{"cert-authority", "principals=\"me\"", "tunnel=\"1\"", "expiry-time=\"tomorrow\""} {
+		if _, err := loadAuthorizedKeys(writeAuthorizedKeys(t, options, pub)); err == nil {
+			t.Errorf("loaded a key with %s", options)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/pions/dtls
+
+go 1.27.1
+
+require golang.org/x/crypto v0.0.0-20181203042331-505ab145d0a9
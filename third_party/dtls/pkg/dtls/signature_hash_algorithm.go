@@ -0,0 +1,6 @@
+package dtls
+
+type signatureHashAlgorithm struct {
+	hash      HashAlgorithm
+	signature signatureAlgorithm
+}
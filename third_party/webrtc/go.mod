@@ -1,12 +1,17 @@
 module github.com/pions/webrtc
 
+go 1.27.1
+
 require (
-	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pions/dtls v1.0.2
 	github.com/pions/pkg v0.0.0-20181115215726-b60cd756f712
 	github.com/pkg/errors v0.8.0
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/testify v1.2.2
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/crypto v0.0.0-20181203042331-505ab145d0a9 // indirect
 	golang.org/x/net v0.0.0-20181220203305-927f97764cc3 // indirect
 )
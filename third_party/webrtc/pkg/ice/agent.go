@@ -0,0 +1,693 @@
+// Package ice implements the Interactive Connectivity Establishment (ICE)
+// protocol defined in rfc5245.
+package ice
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pions/pkg/stun"
+	"github.com/pions/webrtc/internal/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// taskLoopInterval is the interval at which the agent performs checks
+	taskLoopInterval = 2 * time.Second
+
+	// keepaliveInterval used to keep candidates alive
+	keepaliveInterval = 10 * time.Second
+
+	// connectionTimeout used to declare a connection dead
+	connectionTimeout = 30 * time.Second
+)
+
+// Agent represents the ICE agent
+type Agent struct {
+	notifier func(ConnectionState)
+
+	// Used to block double Dial/Accept
+	opened bool
+
+	// State owned by the taskLoop
+	taskChan        chan task
+	onConnected     chan struct{}
+	onConnectedOnce sync.Once
+
+	connectivityTicker *time.Ticker
+	connectivityChan   <-chan time.Time
+
+	tieBreaker      uint64
+	connectionState ConnectionState
+	gatheringState  GatheringState
+
+	haveStarted   bool
+	isControlling bool
+
+	localUfrag      string
+	localPwd        string
+	localCandidates map[NetworkType][]*Candidate
+
+	remoteUfrag      string
+	remotePwd        string
+	remoteCandidates map[NetworkType][]*Candidate
+
+	selectedPair *candidatePair
+	validPairs   []*candidatePair
+
+	// Channel for reading
+	rcvCh chan *bufIn
+
+	// State for closing
+	done chan struct{}
+	err  atomicError
+}
+
+type bufIn struct {
+	buf  []byte
+	size chan int
+}
+
+func (a *Agent) ok() error {
+	select {
+	case <-a.done:
+		return a.getErr()
+	default:
+	}
+	return nil
+}
+
+func (a *Agent) getErr() error {
+	err := a.err.Load()
+	if err != nil {
+		return err
+	}
+	return ErrClosed
+}
+
+// NewAgent creates a new Agent
+func NewAgent(urls []*URL, notifier func(ConnectionState)) *Agent {
+	return NewAgentWithSettings(urls, notifier, AgentSettings{})
+}
+
+// NewAgentWithSettings creates a new Agent gathering its candidates on the
+// sockets settings allow.
+func NewAgentWithSettings(urls []*URL, notifier func(ConnectionState), settings AgentSettings) *Agent {
+	a := &Agent{
+		notifier:         notifier,
+		tieBreaker:       rand.New(rand.NewSource(time.Now().UnixNano())).Uint64(),
+		gatheringState:   GatheringStateComplete, // TODO trickle-ice
+		connectionState:  ConnectionStateNew,
+		localCandidates:  make(map[NetworkType][]*Candidate),
+		remoteCandidates: make(map[NetworkType][]*Candidate),
+
+		localUfrag:  util.RandSeq(16),
+		localPwd:    util.RandSeq(32),
+		taskChan:    make(chan task),
+		onConnected: make(chan struct{}),
+		rcvCh:       make(chan *bufIn),
+		done:        make(chan struct{}),
+	}
+
+	// Initialize local candidates
+	localIPs := settings.localAddrs()
+	if !settings.RelayOnly {
+		a.gatherCandidatesLocal(localIPs, &settings)
+	}
+	a.gatherCandidatesServer(urls, localIPs, &settings)
+
+	go a.taskLoop()
+	return a
+}
+
+func (a *Agent) addLocalCandidate(c *Candidate, conn net.PacketConn) {
+	networkType := c.NetworkType
+	set := a.localCandidates[networkType]
+	set = append(set, c)
+	a.localCandidates[networkType] = set
+
+	c.start(a, conn)
+}
+
+func (a *Agent) gatherCandidatesLocal(localIPs []net.IP, settings *AgentSettings) {
+	for _, ip := range localIPs {
+		for _, network := range supportedNetworks {
+			conn, err := settings.listenUDP(network, ip)
+			if err != nil {
+				fmt.Printf("could not listen %s %s\n", network, ip)
+				continue
+			}
+
+			port := conn.LocalAddr().(*net.UDPAddr).Port
+			c, err := NewCandidateHost(network, ip, port)
+			if err != nil {
+				fmt.Printf("Failed to create host candidate: %s %s %d: %v\n", network, ip, port, err)
+				conn.Close()
+				continue
+			}
+
+			a.addLocalCandidate(c, conn)
+		}
+	}
+}
+
+// gatherCandidatesServer asks each STUN server for the reflexive address of
+// a socket on each local address and allocates a relay on each TURN server,
+// all at once since servers unreachable from an address only fail on
+// timeout.
+func (a *Agent) gatherCandidatesServer(urls []*URL, localIPs []net.IP, settings *AgentSettings) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	gather := func(url *URL, gather func() (*Candidate, net.PacketConn, error)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, conn, err := gather()
+			if err != nil {
+				fmt.Printf("could not allocate %s: %v\n", url, err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			a.addLocalCandidate(c, conn)
+		}()
+	}
+	for _, url := range urls {
+		url := url
+		switch url.Scheme {
+		case SchemeTypeSTUN:
+			if settings.RelayOnly {
+				continue
+			}
+			for _, ip := range localIPs {
+				ip := ip
+				gather(url, func() (*Candidate, net.PacketConn, error) {
+					return gatherReflective(url, ip, settings)
+				})
+			}
+		case SchemeTypeTURN, SchemeTypeTURNS:
+			gather(url, func() (*Candidate, net.PacketConn, error) {
+				return gatherRelay(url, localIPs, settings)
+			})
+		default:
+			fmt.Printf("scheme %s is not implemented\n", url.Scheme.String())
+		}
+	}
+	wg.Wait()
+}
+
+// gatherReflective returns a server reflexive candidate of a socket on ip,
+// learnt from the STUN server of url, and the socket.
+func gatherReflective(url *URL, ip net.IP, settings *AgentSettings) (*Candidate, net.PacketConn, error) {
+	network := NetworkTypeUDP6.String()
+	if ip.To4() != nil {
+		network = NetworkTypeUDP4.String()
+	}
+	server, err := net.ResolveUDPAddr(network, net.JoinHostPort(url.Host, strconv.Itoa(url.Port)))
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := settings.listenUDP(network, ip)
+	if err != nil {
+		return nil, nil, err
+	}
+	xoraddr, err := bindUDP(conn, server)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	laddr := conn.LocalAddr().(*net.UDPAddr)
+	c, err := NewCandidateServerReflexive(network, xoraddr.IP, xoraddr.Port, laddr.IP.String(), laddr.Port)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return c, conn, nil
+}
+
+const (
+	// stunTimeout bounds a binding request to a STUN server
+	stunTimeout = 5 * time.Second
+
+	// stunRetransmit is the interval of its retransmissions
+	stunRetransmit = 500 * time.Millisecond
+)
+
+// bindUDP sends a binding request from conn to the STUN server at server
+// and returns the reflexive address it tells.
+func bindUDP(conn *net.UDPConn, server *net.UDPAddr) (*stun.XorAddress, error) {
+	req, err := stun.Build(stun.ClassRequest, stun.MethodBinding, stun.GenerateTransactionId())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.SetReadDeadline(time.Time{})
+
+	deadline := time.Now().Add(stunTimeout)
+	buf := make([]byte, receiveMTU)
+	for time.Now().Before(deadline) {
+		if _, err = conn.WriteTo(req.Pack(), server); err != nil {
+			return nil, errors.Wrapf(err, "Failed to make STUN request")
+		}
+		next := time.Now().Add(stunRetransmit)
+		if next.After(deadline) {
+			next = deadline
+		}
+		if err = conn.SetReadDeadline(next); err != nil {
+			return nil, err
+		}
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				break
+			}
+			if !stun.IsSTUN(buf[:n]) {
+				continue
+			}
+			res, err := stun.NewMessage(append([]byte(nil), buf[:n]...))
+			if err != nil || res.Class != stun.ClassSuccessResponse || !bytes.Equal(res.TransactionID, req.TransactionID) {
+				continue
+			}
+			attr, ok := res.GetOneAttribute(stun.AttrXORMappedAddress)
+			if !ok {
+				return nil, errors.Errorf("Got respond from STUN server that did not contain XORAddress")
+			}
+			var addr stun.XorAddress
+			if err = addr.Unpack(res, attr); err != nil {
+				return nil, errors.Wrapf(err, "Failed to unpack STUN XorAddress response")
+			}
+			return &addr, nil
+		}
+	}
+	return nil, errors.Errorf("no response from %s", server)
+}
+
+func (a *Agent) startConnectivityChecks(isControlling bool, remoteUfrag, remotePwd string) error {
+	if a.haveStarted {
+		return errors.Errorf("Attempted to start agent twice")
+	} else if remoteUfrag == "" {
+		return errors.Errorf("remoteUfrag is empty")
+	} else if remotePwd == "" {
+		return errors.Errorf("remotePwd is empty")
+	}
+
+	return a.run(func(agent *Agent) {
+		agent.isControlling = isControlling
+		agent.remoteUfrag = remoteUfrag
+		agent.remotePwd = remotePwd
+
+		// TODO this should be dynamic, and grow when the connection is stable
+		t := time.NewTicker(taskLoopInterval)
+		agent.connectivityTicker = t
+		agent.connectivityChan = t.C
+
+		agent.updateConnectionState(ConnectionStateChecking)
+	})
+}
+
+func (a *Agent) pingCandidate(local, remote *Candidate) {
+	var msg *stun.Message
+	var err error
+
+	// The controlling agent MUST include the USE-CANDIDATE attribute in
+	// order to nominate a candidate pair (Section 8.1.1).  The controlled
+	// agent MUST NOT include the USE-CANDIDATE attribute in a Binding
+	// request.
+
+	if a.isControlling {
+		msg, err = stun.Build(stun.ClassRequest, stun.MethodBinding, stun.GenerateTransactionId(),
+			&stun.Username{Username: a.remoteUfrag + ":" + a.localUfrag},
+			&stun.UseCandidate{},
+			&stun.IceControlling{TieBreaker: a.tieBreaker},
+			&stun.Priority{Priority: uint32(local.Priority(CandidateTypeHost.Preference(), 1))},
+			&stun.MessageIntegrity{
+				Key: []byte(a.remotePwd),
+			},
+			&stun.Fingerprint{},
+		)
+	} else {
+		msg, err = stun.Build(stun.ClassRequest, stun.MethodBinding, stun.GenerateTransactionId(),
+			&stun.Username{Username: a.remoteUfrag + ":" + a.localUfrag},
+			&stun.IceControlled{TieBreaker: a.tieBreaker},
+			&stun.Priority{Priority: uint32(local.Priority(CandidateTypeHost.Preference(), 1))},
+			&stun.MessageIntegrity{
+				Key: []byte(a.remotePwd),
+			},
+			&stun.Fingerprint{},
+		)
+	}
+
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	a.sendSTUN(msg, local, remote)
+}
+
+func (a *Agent) updateConnectionState(newState ConnectionState) {
+	if a.connectionState != newState {
+		a.connectionState = newState
+		if a.notifier != nil {
+			// Call handler async since we may be holding the agent lock
+			// and the handler may also require it
+			go a.notifier(a.connectionState)
+		}
+	}
+}
+
+func (a *Agent) setValidPair(local, remote *Candidate, selected bool) {
+	// TODO: avoid duplicates
+	p := newCandidatePair(local, remote)
+
+	if selected {
+		a.selectedPair = p
+		a.validPairs = nil
+		// TODO: only set state to connected on selecting final pair?
+		a.updateConnectionState(ConnectionStateConnected)
+	} else {
+		// keep track of pairs with succesfull bindings since any of them
+		// can be used for communication until the final pair is selected:
+		// https://tools.ietf.org/html/draft-ietf-ice-rfc5245bis-20#section-12
+		a.validPairs = append(a.validPairs, p)
+	}
+
+	// Signal connected
+	a.onConnectedOnce.Do(func() { close(a.onConnected) })
+}
+
+// A task is a
+type task func(*Agent)
+
+func (a *Agent) run(t task) error {
+	err := a.ok()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-a.done:
+		return a.getErr()
+	case a.taskChan <- t:
+	}
+	return nil
+}
+
+func (a *Agent) taskLoop() {
+	for {
+		select {
+		case <-a.connectivityChan:
+			if a.validateSelectedPair() {
+				a.checkKeepalive()
+			} else {
+				a.pingAllCandidates()
+			}
+
+		case t := <-a.taskChan:
+			// Run the task
+			t(a)
+
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// validateSelectedPair checks if the selected pair is (still) valid
+// Note: the caller should hold the agent lock.
+func (a *Agent) validateSelectedPair() bool {
+	if a.selectedPair == nil {
+		// Not valid since not selected
+		return false
+	}
+
+	if time.Since(a.selectedPair.remote.LastReceived()) > connectionTimeout {
+		a.selectedPair = nil
+		a.updateConnectionState(ConnectionStateDisconnected)
+		return false
+	}
+
+	return true
+}
+
+// checkKeepalive sends STUN Binding Indications to the selected pair
+// if no packet has been sent on that pair in the last keepaliveInterval
+// Note: the caller should hold the agent lock.
+func (a *Agent) checkKeepalive() {
+	if a.selectedPair == nil {
+		return
+	}
+
+	if time.Since(a.selectedPair.remote.LastSent()) > keepaliveInterval {
+		a.keepaliveCandidate(a.selectedPair.local, a.selectedPair.remote)
+	}
+}
+
+// pingAllCandidates sends STUN Binding Requests to all candidates
+// Note: the caller should hold the agent lock.
+func (a *Agent) pingAllCandidates() {
+	for networkType, localCandidates := range a.localCandidates {
+		if remoteCandidates, ok := a.remoteCandidates[networkType]; ok {
+
+			for _, localCandidate := range localCandidates {
+				for _, remoteCandidate := range remoteCandidates {
+					a.pingCandidate(localCandidate, remoteCandidate)
+				}
+			}
+
+		}
+	}
+}
+
+// AddRemoteCandidate adds a new remote candidate
+func (a *Agent) AddRemoteCandidate(c *Candidate) error {
+	return a.run(func(agent *Agent) {
+		networkType := c.NetworkType
+		set := agent.remoteCandidates[networkType]
+
+		for _, candidate := range set {
+			if candidate.Equal(c) {
+				return
+			}
+		}
+
+		set = append(set, c)
+		agent.remoteCandidates[networkType] = set
+	})
+}
+
+// GetLocalCandidates returns the local candidates
+func (a *Agent) GetLocalCandidates() ([]*Candidate, error) {
+	res := make(chan []*Candidate)
+
+	err := a.run(func(agent *Agent) {
+		var candidates []*Candidate
+		for _, set := range agent.localCandidates {
+			candidates = append(candidates, set...)
+		}
+		res <- candidates
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return <-res, nil
+}
+
+// GetLocalUserCredentials returns the local user credentials
+func (a *Agent) GetLocalUserCredentials() (frag string, pwd string) {
+	return a.localUfrag, a.localPwd
+}
+
+// Close cleans up the Agent
+func (a *Agent) Close() error {
+	done := make(chan struct{})
+	err := a.run(func(agent *Agent) {
+		defer func() {
+			close(done)
+		}()
+		agent.err.Store(ErrClosed)
+		close(agent.done)
+
+		// Cleanup all candidates
+		for net, cs := range agent.localCandidates {
+			for _, c := range cs {
+				err := c.close()
+				if err != nil {
+					fmt.Printf("Failed to close candidate %s: %v", c, err)
+				}
+			}
+			delete(agent.localCandidates, net)
+		}
+		for net, cs := range agent.remoteCandidates {
+			for _, c := range cs {
+				err := c.close()
+				if err != nil {
+					fmt.Printf("Failed to close candidate %s: %v", c, err)
+				}
+			}
+			delete(agent.remoteCandidates, net)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	<-done
+
+	return nil
+}
+
+func (a *Agent) findRemoteCandidate(networkType NetworkType, addr net.Addr) *Candidate {
+	var ip net.IP
+	var port int
+
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		ip = a.IP
+		port = a.Port
+	case *net.TCPAddr:
+		ip = a.IP
+		port = a.Port
+	default:
+		fmt.Printf("unsupported address type %T", a)
+		return nil
+	}
+
+	set := a.remoteCandidates[networkType]
+	for _, c := range set {
+		base := c
+		if base.IP.Equal(ip) &&
+			base.Port == port {
+			return c
+		}
+	}
+	return nil
+}
+
+func (a *Agent) sendBindingSuccess(m *stun.Message, local, remote *Candidate) {
+	base := remote
+	if out, err := stun.Build(stun.ClassSuccessResponse, stun.MethodBinding, m.TransactionID,
+		&stun.XorMappedAddress{
+			XorAddress: stun.XorAddress{
+				IP:   base.IP,
+				Port: base.Port,
+			},
+		},
+		&stun.MessageIntegrity{
+			Key: []byte(a.localPwd),
+		},
+		&stun.Fingerprint{},
+	); err != nil {
+		fmt.Printf("Failed to handle inbound ICE from: %s to: %s error: %s", local.String(), remote.String(), err.Error())
+	} else {
+		a.sendSTUN(out, local, remote)
+	}
+}
+
+func (a *Agent) handleInboundControlled(m *stun.Message, localCandidate, remoteCandidate *Candidate) {
+	if _, isControlled := m.GetOneAttribute(stun.AttrIceControlled); isControlled && !a.isControlling {
+		fmt.Println("inbound isControlled && a.isControlling == false")
+		return
+	}
+
+	successResponse := m.Method == stun.MethodBinding && m.Class == stun.ClassSuccessResponse
+	_, usepair := m.GetOneAttribute(stun.AttrUseCandidate)
+	// Remember the working pair and select it when marked with usepair
+	a.setValidPair(localCandidate, remoteCandidate, usepair)
+
+	if !successResponse {
+		// Send success response
+		a.sendBindingSuccess(m, localCandidate, remoteCandidate)
+	}
+}
+
+func (a *Agent) handleInboundControlling(m *stun.Message, localCandidate, remoteCandidate *Candidate) {
+	if _, isControlling := m.GetOneAttribute(stun.AttrIceControlling); isControlling && a.isControlling {
+		fmt.Println("inbound isControlling && a.isControlling == true")
+		return
+	} else if _, useCandidate := m.GetOneAttribute(stun.AttrUseCandidate); useCandidate && a.isControlling {
+		fmt.Println("useCandidate && a.isControlling == true")
+		return
+	}
+
+	successResponse := m.Method == stun.MethodBinding && m.Class == stun.ClassSuccessResponse
+	// Remember the working pair and select it when receiving a success response
+	a.setValidPair(localCandidate, remoteCandidate, successResponse)
+
+	if !successResponse {
+		// Send success response
+		a.sendBindingSuccess(m, localCandidate, remoteCandidate)
+
+		// We received a ping from the controlled agent. We know the pair works so now we ping with use-candidate set:
+		a.pingCandidate(localCandidate, remoteCandidate)
+	}
+}
+
+// handleInbound processes traffic from a remote candidate
+func (a *Agent) handleInbound(m *stun.Message, local *Candidate, remote net.Addr) {
+	remoteCandidate := a.findRemoteCandidate(local.NetworkType, remote)
+	if remoteCandidate == nil {
+		// TODO debug
+		// fmt.Printf("Could not find remote candidate for %s:%d ", remote.IP.String(), remote.Port)
+		return
+	}
+
+	remoteCandidate.seen(false)
+
+	if m.Class == stun.ClassIndication {
+		return
+	}
+
+	if a.isControlling {
+		a.handleInboundControlling(m, local, remoteCandidate)
+	} else {
+		a.handleInboundControlled(m, local, remoteCandidate)
+	}
+
+}
+
+// SelectedPair returns the local and remote candidates of the pair that
+// carries the traffic, nil before one is selected.
+func (a *Agent) SelectedPair() (local, remote *Candidate, err error) {
+	res := make(chan *candidatePair, 1)
+	err = a.run(func(agent *Agent) {
+		p := agent.selectedPair
+		if p == nil && len(agent.validPairs) > 0 {
+			p = agent.validPairs[0]
+		}
+		res <- p
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if p := <-res; p != nil {
+		return p.local, p.remote, nil
+	}
+	return nil, nil, nil
+}
+
+func (a *Agent) getBestPair() (*candidatePair, error) {
+	res := make(chan *candidatePair)
+
+	err := a.run(func(agent *Agent) {
+		if agent.selectedPair != nil {
+			res <- agent.selectedPair
+			return
+		}
+		for _, p := range agent.validPairs {
+			res <- p
+			return
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return <-res, nil
+}
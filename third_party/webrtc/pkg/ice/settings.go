@@ -0,0 +1,77 @@
+package ice
+
+import (
+	"math/rand"
+	"net"
+)
+
+// AgentSettings restricts the sockets an Agent gathers its candidates on.
+// The zero value listens on any port of every address of the local
+// interfaces that are up, as NewAgent does.
+type AgentSettings struct {
+	// PortMin and PortMax bound the local ports of candidates, any port
+	// if PortMax is zero.
+	PortMin, PortMax uint16
+
+	// NetworkTypes lists the networks candidates may use, every supported
+	// one if empty.
+	NetworkTypes []NetworkType
+
+	// AddressFilter, if set, tells whether candidates may use the address
+	// ip of the local interface named iface.
+	AddressFilter func(iface string, ip net.IP) bool
+
+	// RelayOnly only gathers relay candidates, on the TURN servers.
+	RelayOnly bool
+}
+
+// useNetwork reports whether candidates may use the network of ip.
+func (s *AgentSettings) useNetwork(ip net.IP) bool {
+	if len(s.NetworkTypes) == 0 {
+		return true
+	}
+	t := NetworkTypeUDP6
+	if ip.To4() != nil {
+		t = NetworkTypeUDP4
+	}
+	for _, v := range s.NetworkTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// localAddrs returns the local addresses candidates may use.
+func (s *AgentSettings) localAddrs() []net.IP {
+	var ips []net.IP
+	for _, a := range localInterfaces() {
+		if !s.useNetwork(a.ip) {
+			continue
+		}
+		if s.AddressFilter != nil && !s.AddressFilter(a.iface, a.ip) {
+			continue
+		}
+		ips = append(ips, a.ip)
+	}
+	return ips
+}
+
+// listenUDP listens on ip at a port of the range, tried from a random one.
+func (s *AgentSettings) listenUDP(network string, ip net.IP) (*net.UDPConn, error) {
+	if s.PortMax == 0 {
+		return net.ListenUDP(network, &net.UDPAddr{IP: ip})
+	}
+	if s.PortMin == 0 || s.PortMin > s.PortMax {
+		return nil, ErrPort
+	}
+	n := int(s.PortMax-s.PortMin) + 1
+	start := rand.Intn(n)
+	for i := 0; i < n; i++ {
+		port := int(s.PortMin) + (start+i)%n
+		if conn, err := net.ListenUDP(network, &net.UDPAddr{IP: ip, Port: port}); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, ErrNoPort
+}
@@ -0,0 +1,586 @@
+package ice
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pions/pkg/stun"
+	"github.com/pkg/errors"
+)
+
+const (
+	// turnTimeout bounds a request to a TURN server
+	turnTimeout = 5 * time.Second
+
+	// turnRetransmit is the interval of its retransmissions over UDP
+	turnRetransmit = 500 * time.Millisecond
+
+	// turnLifetime is the lifetime asked for allocations
+	turnLifetime = 10 * time.Minute
+
+	// turnRefresh is the interval allocations and permissions, which last
+	// 5 minutes, are refreshed at
+	turnRefresh = 4 * time.Minute
+)
+
+// turnTransport carries the messages exchanged with a TURN server.
+type turnTransport interface {
+	writeMsg(b []byte) error
+	readMsg(buf []byte) (int, error)
+	// reliable reports whether requests need no retransmission.
+	reliable() bool
+	// protocol names the transport: "udp", "tcp" or "tls".
+	protocol() string
+	Close() error
+}
+
+// udpTransport reaches a TURN server over UDP.
+type udpTransport struct {
+	conn   *net.UDPConn
+	server *net.UDPAddr
+}
+
+func (t *udpTransport) writeMsg(b []byte) error {
+	_, err := t.conn.WriteTo(b, t.server)
+	return err
+}
+
+func (t *udpTransport) readMsg(buf []byte) (int, error) {
+	for {
+		n, addr, err := t.conn.ReadFromUDP(buf)
+		if err != nil {
+			return 0, err
+		}
+		if addr.IP.Equal(t.server.IP) && addr.Port == t.server.Port {
+			return n, nil
+		}
+	}
+}
+
+func (t *udpTransport) reliable() bool { return false }
+
+func (t *udpTransport) protocol() string { return "udp" }
+
+func (t *udpTransport) Close() error { return t.conn.Close() }
+
+// streamTransport reaches a TURN server over TCP or TLS, where messages
+// are framed by their length (rfc5766 section 2.1).
+type streamTransport struct {
+	conn  net.Conn
+	r     *bufio.Reader
+	proto string
+}
+
+func (t *streamTransport) writeMsg(b []byte) error {
+	_, err := t.conn.Write(b)
+	return err
+}
+
+func (t *streamTransport) readMsg(buf []byte) (int, error) {
+	if _, err := io.ReadFull(t.r, buf[:4]); err != nil {
+		return 0, err
+	}
+	n := 4 + int(binary.BigEndian.Uint16(buf[2:4]))
+	if buf[0]&0xc0 == 0 {
+		// STUN, whose length leaves out the rest of the 20-byte header.
+		n += 16
+	} else {
+		// ChannelData, padded to 4 bytes over streams.
+		n = (n + 3) &^ 3
+	}
+	if n > len(buf) {
+		return 0, errors.Errorf("message of %d bytes from the TURN server", n)
+	}
+	if _, err := io.ReadFull(t.r, buf[4:n]); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (t *streamTransport) reliable() bool { return true }
+
+func (t *streamTransport) protocol() string { return t.proto }
+
+func (t *streamTransport) Close() error { return t.conn.Close() }
+
+// dialTURN connects to the TURN server of url from one of localIPs,
+// preferably the one the system routes to it through: over UDP from a port
+// of the range of settings, over TCP and TLS from any port.
+func dialTURN(url *URL, localIPs []net.IP, settings *AgentSettings) (turnTransport, error) {
+	hostPort := net.JoinHostPort(url.Host, strconv.Itoa(url.Port))
+	if url.Scheme == SchemeTypeTURNS && url.Proto != ProtoTypeTCP {
+		return nil, errors.Errorf("transport %s of %s is not implemented", url.Proto, url.Scheme)
+	}
+	var lastErr error
+	for _, ip := range routeFirst(hostPort, localIPs) {
+		v6 := ip.To4() == nil
+		if url.Proto == ProtoTypeUDP {
+			network := NetworkTypeUDP4.String()
+			if v6 {
+				network = NetworkTypeUDP6.String()
+			}
+			server, err := net.ResolveUDPAddr(network, hostPort)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			conn, err := settings.listenUDP(network, ip)
+			if err != nil {
+				return nil, err
+			}
+			return &udpTransport{conn: conn, server: server}, nil
+		}
+
+		network := "tcp4"
+		if v6 {
+			network = "tcp6"
+		}
+		d := net.Dialer{LocalAddr: &net.TCPAddr{IP: ip}, Timeout: turnTimeout}
+		conn, err := d.Dial(network, hostPort)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if url.Scheme == SchemeTypeTURN {
+			return &streamTransport{conn: conn, r: bufio.NewReader(conn), proto: "tcp"}, nil
+		}
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: url.Host})
+		tlsConn.SetDeadline(time.Now().Add(turnTimeout))
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		tlsConn.SetDeadline(time.Time{})
+		return &streamTransport{conn: tlsConn, r: bufio.NewReader(tlsConn), proto: "tls"}, nil
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, errors.Errorf("no local address reaches %s", hostPort)
+}
+
+// routeFirst returns localIPs with the address the system routes to
+// hostPort through first.
+func routeFirst(hostPort string, localIPs []net.IP) []net.IP {
+	conn, err := net.Dial("udp", hostPort)
+	if err != nil {
+		return localIPs
+	}
+	defer conn.Close()
+	local := conn.LocalAddr().(*net.UDPAddr).IP
+	ips := make([]net.IP, 0, len(localIPs))
+	for _, ip := range localIPs {
+		if ip.Equal(local) {
+			ips = append([]net.IP{ip}, ips...)
+		} else {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// turnConn is a PacketConn relaying through an allocation on a TURN server
+// (rfc5766): packets to a peer go out in Send indications once a permission
+// for its address is installed, and those of permitted peers come back in
+// Data indications.
+type turnConn struct {
+	tr       turnTransport
+	user     string
+	password string
+	relayed  *net.UDPAddr
+	mapped   *net.UDPAddr
+
+	mu    sync.Mutex
+	realm string
+	nonce string
+	key   []byte
+	perms map[string]net.IP
+	txs   map[string]chan *stun.Message
+
+	packets   chan turnPacket
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type turnPacket struct {
+	data []byte
+	from *net.UDPAddr
+}
+
+// newTurnConn allocates a relayed transport address on the server at the
+// other end of tr with the credentials of url.
+func newTurnConn(tr turnTransport, url *URL) (*turnConn, error) {
+	if url.Username == "" {
+		tr.Close()
+		return nil, errors.Errorf("no credentials for %s", url)
+	}
+	c := &turnConn{
+		tr:       tr,
+		user:     url.Username,
+		password: url.Password,
+		perms:    make(map[string]net.IP),
+		txs:      make(map[string]chan *stun.Message),
+		packets:  make(chan turnPacket),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop()
+
+	res, err := c.roundTrip(stun.MethodAllocate, []stun.Attribute{
+		requestedTransport{},
+		&stun.Lifetime{Duration: uint32(turnLifetime / time.Second)},
+	}, nil)
+	if err != nil {
+		c.shutdown()
+		return nil, err
+	}
+	var relayed stun.XorRelayedAddress
+	var mapped stun.XorMappedAddress
+	relayedAttr, ok1 := res.GetOneAttribute(stun.AttrXORRelayedAddress)
+	mappedAttr, ok2 := res.GetOneAttribute(stun.AttrXORMappedAddress)
+	if !ok1 || !ok2 || relayed.Unpack(res, relayedAttr) != nil || mapped.Unpack(res, mappedAttr) != nil {
+		c.shutdown()
+		return nil, errors.Errorf("allocate response without addresses")
+	}
+	c.relayed = &net.UDPAddr{IP: relayed.IP, Port: relayed.Port}
+	c.mapped = &net.UDPAddr{IP: mapped.IP, Port: mapped.Port}
+
+	go c.refreshLoop()
+	return c, nil
+}
+
+// auth returns the attributes authenticating a request, none before the
+// server told its realm. c.mu is held.
+func (c *turnConn) auth() []stun.Attribute {
+	if c.key == nil {
+		return nil
+	}
+	return []stun.Attribute{
+		&stun.Username{Username: c.user},
+		&stun.Realm{Realm: c.realm},
+		&stun.Nonce{Nonce: c.nonce},
+		&stun.MessageIntegrity{Key: c.key},
+	}
+}
+
+// roundTrip sends a request of method with attrs, answering the challenges
+// of the server, and returns its success response. sent, if set, is closed
+// once the request has been written first.
+func (c *turnConn) roundTrip(method stun.Method, attrs []stun.Attribute, sent chan struct{}) (*stun.Message, error) {
+	defer func() {
+		if sent != nil {
+			close(sent)
+		}
+	}()
+	for tries := 0; tries < 3; tries++ {
+		c.mu.Lock()
+		authenticated := c.key != nil
+		all := append(append([]stun.Attribute{}, attrs...), c.auth()...)
+		c.mu.Unlock()
+
+		req, err := stun.Build(stun.ClassRequest, method, stun.GenerateTransactionId(), all...)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.exchange(req, sent)
+		sent = nil
+		if err != nil {
+			return nil, err
+		}
+		if res.Class == stun.ClassSuccessResponse {
+			return res, nil
+		}
+
+		code, reason := errorCode(res)
+		realm, hasRealm := res.GetOneAttribute(stun.AttrRealm)
+		nonce, hasNonce := res.GetOneAttribute(stun.AttrNonce)
+		switch {
+		case code == 401 && !authenticated && hasRealm && hasNonce:
+			c.mu.Lock()
+			c.realm, c.nonce = string(realm.Value), string(nonce.Value)
+			key := md5.Sum([]byte(c.user + ":" + c.realm + ":" + c.password))
+			c.key = key[:]
+			c.mu.Unlock()
+		case code == 438 && hasNonce:
+			c.mu.Lock()
+			c.nonce = string(nonce.Value)
+			if hasRealm {
+				c.realm = string(realm.Value)
+			}
+			c.mu.Unlock()
+		default:
+			return nil, errors.Errorf("%s failed: %d %s", method, code, reason)
+		}
+	}
+	return nil, errors.Errorf("%s failed: too many challenges", method)
+}
+
+// exchange writes req until its response arrives, retransmitting it over
+// UDP. sent, if set, is closed once req has been written first.
+func (c *turnConn) exchange(req *stun.Message, sent chan struct{}) (*stun.Message, error) {
+	ch := make(chan *stun.Message, 1)
+	id := string(req.TransactionID)
+	c.mu.Lock()
+	c.txs[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.txs, id)
+		c.mu.Unlock()
+	}()
+
+	deadline := time.Now().Add(turnTimeout)
+	for {
+		err := c.tr.writeMsg(req.Pack())
+		if sent != nil {
+			close(sent)
+			sent = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		wait := time.Until(deadline)
+		if !c.tr.reliable() && wait > turnRetransmit {
+			wait = turnRetransmit
+		}
+		t := time.NewTimer(wait)
+		select {
+		case res := <-ch:
+			t.Stop()
+			return res, nil
+		case <-c.done:
+			t.Stop()
+			return nil, ErrClosed
+		case <-t.C:
+		}
+		if !time.Now().Before(deadline) {
+			return nil, errors.Errorf("%s timed out", req.Method)
+		}
+	}
+}
+
+// errorCode returns the ERROR-CODE of m; stun.ErrorCode cannot unpack
+// itself.
+func errorCode(m *stun.Message) (int, string) {
+	attr, ok := m.GetOneAttribute(stun.AttrErrorCode)
+	if !ok || len(attr.Value) < 4 {
+		return 0, "error response without a code"
+	}
+	v := attr.Value
+	return int(v[2]&7)*100 + int(v[3]), string(v[4:])
+}
+
+// readLoop dispatches what the server sends: responses to their request
+// and the data of Data indications to ReadFrom.
+func (c *turnConn) readLoop() {
+	buf := make([]byte, receiveMTU)
+	for {
+		n, err := c.tr.readMsg(buf)
+		if err != nil {
+			c.Close()
+			return
+		}
+		if !stun.IsSTUN(buf[:n]) {
+			// ChannelData: no channel is ever bound.
+			continue
+		}
+		m, err := stun.NewMessage(append([]byte(nil), buf[:n]...))
+		if err != nil {
+			continue
+		}
+		switch {
+		case m.Class == stun.ClassSuccessResponse || m.Class == stun.ClassErrorResponse:
+			c.mu.Lock()
+			ch := c.txs[string(m.TransactionID)]
+			c.mu.Unlock()
+			if ch != nil {
+				select {
+				case ch <- m:
+				default:
+				}
+			}
+		case m.Class == stun.ClassIndication && m.Method == stun.MethodData:
+			peerAttr, ok1 := m.GetOneAttribute(stun.AttrXORPeerAddress)
+			dataAttr, ok2 := m.GetOneAttribute(stun.AttrData)
+			var peer stun.XorAddress
+			if !ok1 || !ok2 || peer.Unpack(m, peerAttr) != nil {
+				continue
+			}
+			select {
+			case c.packets <- turnPacket{data: dataAttr.Value, from: &net.UDPAddr{IP: peer.IP, Port: peer.Port}}:
+			case <-c.done:
+				return
+			}
+		}
+	}
+}
+
+// refreshLoop keeps the allocation and its permissions alive.
+func (c *turnConn) refreshLoop() {
+	t := time.NewTicker(turnRefresh)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-c.done:
+			return
+		}
+		if _, err := c.roundTrip(stun.MethodRefresh, []stun.Attribute{
+			&stun.Lifetime{Duration: uint32(turnLifetime / time.Second)},
+		}, nil); err != nil {
+			fmt.Printf("could not refresh allocation %s: %v\n", c.relayed, err)
+			continue
+		}
+		c.mu.Lock()
+		var peers []stun.Attribute
+		for _, ip := range c.perms {
+			peers = append(peers, &stun.XorPeerAddress{XorAddress: stun.XorAddress{IP: ip}})
+		}
+		c.mu.Unlock()
+		if len(peers) == 0 {
+			continue
+		}
+		if _, err := c.roundTrip(stun.MethodCreatePermission, peers, nil); err != nil {
+			fmt.Printf("could not refresh permissions of %s: %v\n", c.relayed, err)
+		}
+	}
+}
+
+// permit installs a permission for ip.
+func (c *turnConn) permit(ip net.IP, sent chan struct{}) {
+	_, err := c.roundTrip(stun.MethodCreatePermission, []stun.Attribute{
+		&stun.XorPeerAddress{XorAddress: stun.XorAddress{IP: ip}},
+	}, sent)
+	if err != nil {
+		// Tried again on the next packet to the peer.
+		c.mu.Lock()
+		delete(c.perms, ip.String())
+		c.mu.Unlock()
+	}
+}
+
+// ReadFrom returns the next packet relayed from a peer.
+func (c *turnConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	select {
+	case p := <-c.packets:
+		return copy(b, p.data), p.from, nil
+	case <-c.done:
+		return 0, nil, ErrClosed
+	}
+}
+
+// WriteTo relays b to the peer at addr, asking for a permission first if
+// needed; the server handles the request before the indication following
+// it.
+func (c *turnConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	peer, ok := addr.(*net.UDPAddr)
+	if !ok {
+		return 0, errors.Errorf("unsupported address type %T", addr)
+	}
+	select {
+	case <-c.done:
+		return 0, ErrClosed
+	default:
+	}
+	c.mu.Lock()
+	_, permitted := c.perms[peer.IP.String()]
+	if !permitted {
+		c.perms[peer.IP.String()] = peer.IP
+	}
+	c.mu.Unlock()
+	if !permitted {
+		sent := make(chan struct{})
+		go c.permit(peer.IP, sent)
+		<-sent
+	}
+	m, err := stun.Build(stun.ClassIndication, stun.MethodSend, stun.GenerateTransactionId(),
+		&stun.XorPeerAddress{XorAddress: stun.XorAddress{IP: peer.IP, Port: peer.Port}},
+		&stun.Data{Data: b},
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.tr.writeMsg(m.Pack()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close releases the allocation, without waiting for the server to
+// acknowledge it.
+func (c *turnConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		all := append([]stun.Attribute{&stun.Lifetime{}}, c.auth()...)
+		c.mu.Unlock()
+		if req, buildErr := stun.Build(stun.ClassRequest, stun.MethodRefresh, stun.GenerateTransactionId(), all...); buildErr == nil {
+			c.tr.writeMsg(req.Pack())
+		}
+		close(c.done)
+		err = c.tr.Close()
+	})
+	return err
+}
+
+// shutdown closes c before it holds an allocation.
+func (c *turnConn) shutdown() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.tr.Close()
+	})
+}
+
+// LocalAddr returns the relayed transport address.
+func (c *turnConn) LocalAddr() net.Addr {
+	return c.relayed
+}
+
+// SetDeadline is not supported: the agent sets no deadlines.
+func (c *turnConn) SetDeadline(t time.Time) error { return nil }
+
+// SetReadDeadline is not supported: the agent sets no deadlines.
+func (c *turnConn) SetReadDeadline(t time.Time) error { return nil }
+
+// SetWriteDeadline is not supported: the agent sets no deadlines.
+func (c *turnConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// requestedTransport asks for a UDP relay; stun.RequestedTransport cannot
+// pack itself.
+type requestedTransport struct{}
+
+func (requestedTransport) Pack(m *stun.Message) error {
+	m.AddAttribute(stun.AttrRequestedTransport, []byte{17, 0, 0, 0})
+	return nil
+}
+
+func (requestedTransport) Unpack(*stun.Message, *stun.RawAttribute) error {
+	return nil
+}
+
+// gatherRelay allocates a relay on the TURN server of url and returns its
+// candidate and connection.
+func gatherRelay(url *URL, localIPs []net.IP, settings *AgentSettings) (*Candidate, net.PacketConn, error) {
+	tr, err := dialTURN(url, localIPs, settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := newTurnConn(tr, url)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := NewCandidateRelay(udp, conn.relayed.IP, conn.relayed.Port, conn.mapped.IP.String(), conn.mapped.Port)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	c.RelayProtocol = tr.protocol()
+	return c, conn, nil
+}
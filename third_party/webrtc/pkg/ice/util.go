@@ -0,0 +1,56 @@
+package ice
+
+import (
+	"net"
+	"sync/atomic"
+)
+
+// localAddr is an address of a local interface.
+type localAddr struct {
+	iface string
+	ip    net.IP
+}
+
+func localInterfaces() (addrs []localAddr) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return addrs
+	}
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue // interface down
+		}
+		if iface.Flags&net.FlagLoopback != 0 {
+			continue // loopback interface
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			return addrs
+		}
+		for _, addr := range ifaceAddrs {
+			var ip net.IP
+			switch v := addr.(type) {
+			case *net.IPNet:
+				ip = v.IP
+			case *net.IPAddr:
+				ip = v.IP
+			}
+			if ip == nil || ip.IsLoopback() {
+				continue
+			}
+			addrs = append(addrs, localAddr{iface: iface.Name, ip: ip})
+		}
+	}
+	return addrs
+}
+
+type atomicError struct{ v atomic.Value }
+
+func (a *atomicError) Store(err error) {
+	a.v.Store(struct{ error }{err})
+}
+func (a *atomicError) Load() error {
+	err, _ := a.v.Load().(struct{ error })
+	return err.error
+}
@@ -0,0 +1,7 @@
+package media
+
+// RTCSample contains media, and the amount of samples in it
+type RTCSample struct {
+	Data    []byte
+	Samples uint32
+}
@@ -0,0 +1,30 @@
+package webrtc
+
+// RTCIceTransport allows an application access to information about the ICE
+// transport over which packets are sent and received.
+type RTCIceTransport struct {
+	// Role RTCIceRole
+	// Component RTCIceComponent
+	// State RTCIceTransportState
+	// gatheringState RTCIceGathererState
+}
+
+// func (t *RTCIceTransport) GetLocalCandidates() []RTCIceCandidate {
+//
+// }
+//
+// func (t *RTCIceTransport) GetRemoteCandidates() []RTCIceCandidate {
+//
+// }
+//
+// func (t *RTCIceTransport) GetSelectedCandidatePair() RTCIceCandidatePair {
+//
+// }
+//
+// func (t *RTCIceTransport) GetLocalParameters() RTCIceParameters {
+//
+// }
+//
+// func (t *RTCIceTransport) GetRemoteParameters() RTCIceParameters {
+//
+// }
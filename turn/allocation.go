@@ -0,0 +1,200 @@
+package turn
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pions/pkg/stun"
+)
+
+// allocation is a relayed transport address held by a client. Peers may
+// only reach the client through it once the client permitted their IP, and
+// only peers the server allows.
+type allocation struct {
+	s      *Server
+	client client
+	user   string
+	relay  net.PacketConn
+	txid   string // of the request that made it
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	expires  time.Time
+	perms    map[string]time.Time // expiry by peer IP
+	channels map[uint16]*channel
+	byPeer   map[string]*channel // by peer address
+}
+
+// channel binds a channel number to a peer address.
+type channel struct {
+	num     uint16
+	peer    *net.UDPAddr
+	expires time.Time
+}
+
+func newAllocation(s *Server, client client, user string, relay net.PacketConn, txid string, lifetime time.Duration) *allocation {
+	a := &allocation{
+		s:        s,
+		client:   client,
+		user:     user,
+		relay:    relay,
+		txid:     txid,
+		expires:  time.Now().Add(lifetime),
+		perms:    map[string]time.Time{},
+		channels: map[uint16]*channel{},
+		byPeer:   map[string]*channel{},
+	}
+	a.timer = time.AfterFunc(lifetime, func() { s.remove(a, "allocation expired") })
+	go a.run()
+	return a
+}
+
+// attrs returns the attributes of a successful Allocate response.
+func (a *allocation) attrs(client net.Addr) []stun.Attribute {
+	a.mu.Lock()
+	left := time.Until(a.expires)
+	a.mu.Unlock()
+	return []stun.Attribute{
+		&stun.XorRelayedAddress{XorAddress: stun.XorAddress{IP: a.s.conf.RelayIP, Port: a.port()}},
+		&stun.Lifetime{Duration: uint32((left + time.Second - 1) / time.Second)},
+		&stun.XorMappedAddress{XorAddress: xorAddr(client)},
+	}
+}
+
+// port returns the port of the relayed transport address.
+func (a *allocation) port() int {
+	return a.relay.LocalAddr().(*net.UDPAddr).Port
+}
+
+// relayAddr returns the advertised relayed transport address.
+func (a *allocation) relayAddr() string {
+	return net.JoinHostPort(a.s.conf.RelayIP.String(), strconv.Itoa(a.port()))
+}
+
+func (a *allocation) refresh(lifetime time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expires = time.Now().Add(lifetime)
+	a.timer.Reset(lifetime)
+}
+
+func (a *allocation) close() {
+	a.timer.Stop()
+	a.relay.Close()
+}
+
+// permit installs or refreshes the permission of ip.
+func (a *allocation) permit(ip net.IP) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.perms[ip.String()] = time.Now().Add(permissionLifetime)
+}
+
+// permitted reports whether ip has a permission. a.mu is held.
+func (a *allocation) permitted(ip net.IP) bool {
+	return time.Now().Before(a.perms[ip.String()])
+}
+
+// bind binds num to peer, or refreshes the binding. A channel number and
+// a peer stay bound to each other until the binding expires.
+func (a *allocation) bind(num uint16, peer *net.UDPAddr) error {
+	if num < minChannel || num > maxChannel {
+		return errors.New("channel number out of range")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	ch := a.channels[num]
+	if ch != nil && now.After(ch.expires) {
+		delete(a.byPeer, ch.peer.String())
+		delete(a.channels, num)
+		ch = nil
+	}
+	if other := a.byPeer[peer.String()]; other != nil && other != ch {
+		if now.Before(other.expires) {
+			return errors.New("peer bound to another channel")
+		}
+		delete(a.channels, other.num)
+	}
+	if ch != nil && ch.peer.String() != peer.String() {
+		return errors.New("channel bound to another peer")
+	}
+	if ch == nil {
+		ch = &channel{num: num, peer: peer}
+		a.channels[num] = ch
+		a.byPeer[peer.String()] = ch
+	}
+	ch.expires = now.Add(channelLifetime)
+	a.perms[peer.IP.String()] = now.Add(permissionLifetime)
+	return nil
+}
+
+// channelPeer returns the peer bound to num, or nil.
+func (a *allocation) channelPeer(num uint16) *net.UDPAddr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ch := a.channels[num]
+	if ch == nil || time.Now().After(ch.expires) || !a.permitted(ch.peer.IP) {
+		return nil
+	}
+	return ch.peer
+}
+
+// relayTo sends b from the relayed transport address to a permitted peer.
+func (a *allocation) relayTo(peer *net.UDPAddr, b []byte) {
+	if !a.s.allowed(peer) {
+		return
+	}
+	a.mu.Lock()
+	ok := a.permitted(peer.IP)
+	a.mu.Unlock()
+	if ok {
+		a.relay.WriteTo(b, peer)
+	}
+}
+
+// run passes the packets of permitted peers to the client, over their
+// channel if they have one and in Data indications otherwise.
+func (a *allocation) run() {
+	buf := make([]byte, maxPacket)
+	for {
+		n, addr, err := a.relay.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		peer, ok := addr.(*net.UDPAddr)
+		if !ok || !a.s.allowed(peer) {
+			continue
+		}
+		a.mu.Lock()
+		ok = a.permitted(peer.IP)
+		ch := a.byPeer[peer.String()]
+		if ch != nil && time.Now().After(ch.expires) {
+			ch = nil
+		}
+		a.mu.Unlock()
+		if !ok {
+			continue
+		}
+		if ch != nil {
+			msg := make([]byte, 4+(n+3)&^3)
+			binary.BigEndian.PutUint16(msg, ch.num)
+			binary.BigEndian.PutUint16(msg[2:], uint16(n))
+			copy(msg[4:], buf[:n])
+			a.client.conn.WriteTo(msg, a.client.addr)
+			continue
+		}
+		m, err := stun.Build(stun.ClassIndication, stun.MethodData, stun.GenerateTransactionId(),
+			&stun.XorPeerAddress{XorAddress: stun.XorAddress{IP: peer.IP, Port: peer.Port}},
+			&stun.Data{Data: append([]byte(nil), buf[:n]...)},
+		)
+		if err != nil {
+			continue
+		}
+		a.client.conn.WriteTo(m.Pack(), a.client.addr)
+	}
+}
@@ -0,0 +1,224 @@
+package turn
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/pions/pkg/stun"
+)
+
+// retransmit is the interval of STUN retransmissions over UDP.
+const retransmit = 500 * time.Millisecond
+
+// ResponseError is an error response of a STUN or TURN server.
+type ResponseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Reason)
+}
+
+// Bind sends binding requests from conn to each of servers and returns the
+// reflexive addresses they report, nil for those that did not answer within
+// timeout.
+func Bind(conn net.PacketConn, servers []*net.UDPAddr, timeout time.Duration) []*net.UDPAddr {
+	mapped := make([]*net.UDPAddr, len(servers))
+	reqs := make([]*stun.Message, len(servers))
+	for i := range servers {
+		m, err := stun.Build(stun.ClassRequest, stun.MethodBinding, stun.GenerateTransactionId())
+		if err != nil {
+			return mapped
+		}
+		reqs[i] = m
+	}
+	deadline := time.Now().Add(timeout)
+	buf := make([]byte, maxPacket)
+	for left := len(servers); left > 0 && time.Now().Before(deadline); {
+		for i, m := range reqs {
+			if mapped[i] == nil {
+				conn.WriteTo(m.Pack(), servers[i])
+			}
+		}
+		conn.SetReadDeadline(earliest(time.Now().Add(retransmit), deadline))
+		for left > 0 {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				break
+			}
+			res := parse(buf[:n])
+			if res == nil || res.Class != stun.ClassSuccessResponse {
+				continue
+			}
+			for i, m := range reqs {
+				if mapped[i] == nil && bytes.Equal(res.TransactionID, m.TransactionID) {
+					if mapped[i] = mappedAddr(res); mapped[i] != nil {
+						left--
+					}
+				}
+			}
+		}
+	}
+	conn.SetReadDeadline(time.Time{})
+	return mapped
+}
+
+// Allocate checks the long-term credentials of user on the TURN server at
+// the other end of conn, a UDP, TCP or TLS connection: it allocates a relay
+// and deletes it again, and returns the relayed transport address. A
+// rejection is a *ResponseError.
+func Allocate(conn net.Conn, user, pass string, timeout time.Duration) (*net.UDPAddr, error) {
+	t := &transaction{conn: conn, deadline: time.Now().Add(timeout)}
+	if _, datagram := conn.(net.PacketConn); !datagram {
+		t.r = bufio.NewReader(conn)
+	}
+	defer conn.SetDeadline(time.Time{})
+	relay, auth, err := t.allocate(user, pass)
+	if err != nil {
+		return nil, err
+	}
+	t.do(stun.MethodRefresh, append([]stun.Attribute{&stun.Lifetime{}}, auth...)...)
+	return relay, nil
+}
+
+// allocate allocates a relay, answering the challenge of the server, and
+// returns it with the attributes that authenticate further requests.
+func (t *transaction) allocate(user, pass string) (*net.UDPAddr, []stun.Attribute, error) {
+	res, err := t.do(stun.MethodAllocate, requestedTransport{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var key []byte
+	var auth []stun.Attribute
+	for tries := 0; res.Class == stun.ClassErrorResponse && tries < 2; tries++ {
+		code := responseError(res)
+		realm, ok1 := res.GetOneAttribute(stun.AttrRealm)
+		nonce, ok2 := res.GetOneAttribute(stun.AttrNonce)
+		if (code.Code != 401 && code.Code != 438) || !ok1 || !ok2 || (code.Code == 401 && key != nil) {
+			return nil, nil, code
+		}
+		key = longTermKey(user, string(realm.Value), pass)
+		auth = []stun.Attribute{
+			&stun.Username{Username: user},
+			&stun.Realm{Realm: string(realm.Value)},
+			&stun.Nonce{Nonce: string(nonce.Value)},
+			&stun.MessageIntegrity{Key: key},
+		}
+		if res, err = t.do(stun.MethodAllocate, append([]stun.Attribute{requestedTransport{}}, auth...)...); err != nil {
+			return nil, nil, err
+		}
+	}
+	if res.Class == stun.ClassErrorResponse {
+		return nil, nil, responseError(res)
+	}
+	attr, ok := res.GetOneAttribute(stun.AttrXORRelayedAddress)
+	var relay stun.XorRelayedAddress
+	if !ok || relay.Unpack(res, attr) != nil {
+		return nil, nil, errors.New("turn: allocate response without a relayed address")
+	}
+	return &net.UDPAddr{IP: relay.IP, Port: relay.Port}, auth, nil
+}
+
+// transaction exchanges requests and responses with one server, retrying
+// over UDP.
+type transaction struct {
+	conn     net.Conn
+	r        *bufio.Reader // of a stream, nil over UDP
+	deadline time.Time
+}
+
+func (t *transaction) do(method stun.Method, attrs ...stun.Attribute) (*stun.Message, error) {
+	req, err := stun.Build(stun.ClassRequest, method, stun.GenerateTransactionId(), attrs...)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxPacket)
+	for {
+		if _, err := t.conn.Write(req.Pack()); err != nil {
+			return nil, err
+		}
+		if t.r != nil {
+			t.conn.SetReadDeadline(t.deadline)
+		} else {
+			t.conn.SetReadDeadline(earliest(time.Now().Add(retransmit), t.deadline))
+		}
+		for {
+			var b []byte
+			if t.r != nil {
+				b, err = readFrame(t.r)
+			} else {
+				var n int
+				n, err = t.conn.Read(buf)
+				b = buf[:n]
+			}
+			if err != nil {
+				break
+			}
+			res := parse(b)
+			if res != nil && res.Class != stun.ClassRequest && res.Class != stun.ClassIndication &&
+				bytes.Equal(res.TransactionID, req.TransactionID) {
+				return res, nil
+			}
+		}
+		if ne, ok := err.(net.Error); !ok || !ne.Timeout() || t.r != nil || !time.Now().Before(t.deadline) {
+			return nil, err
+		}
+	}
+}
+
+// requestedTransport asks for a UDP relay; stun.RequestedTransport cannot
+// pack itself.
+type requestedTransport struct{}
+
+func (requestedTransport) Pack(m *stun.Message) error {
+	m.AddAttribute(stun.AttrRequestedTransport, []byte{17, 0, 0, 0})
+	return nil
+}
+
+func (requestedTransport) Unpack(*stun.Message, *stun.RawAttribute) error {
+	return nil
+}
+
+// parse returns the STUN message b, or nil.
+func parse(b []byte) *stun.Message {
+	if !wellFormed(b) {
+		return nil
+	}
+	m, err := stun.NewMessage(append([]byte(nil), b...))
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+func mappedAddr(m *stun.Message) *net.UDPAddr {
+	attr, ok := m.GetOneAttribute(stun.AttrXORMappedAddress)
+	var a stun.XorMappedAddress
+	if !ok || a.Unpack(m, attr) != nil {
+		return nil
+	}
+	return &net.UDPAddr{IP: a.IP, Port: a.Port}
+}
+
+// responseError returns the ERROR-CODE of m; stun.ErrorCode cannot unpack
+// itself.
+func responseError(m *stun.Message) *ResponseError {
+	attr, ok := m.GetOneAttribute(stun.AttrErrorCode)
+	if !ok || len(attr.Value) < 4 {
+		return &ResponseError{Code: 500, Reason: "error response without a code"}
+	}
+	v := attr.Value
+	return &ResponseError{Code: int(v[2]&7)*100 + int(v[3]), Reason: string(v[4:])}
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,805 @@
+// Package turn is a STUN binding responder and a TURN relay (RFC 5389 and
+// RFC 5766) over UDP, TCP and TLS, for peers that have no STUN or TURN
+// provider.
+//
+// Binding requests are answered for anyone. Allocations need the long-term
+// credentials of one of the configured users and only relay UDP; TCP
+// allocations (RFC 6062) and DTLS are not supported. Peers on loopback,
+// private and link-local addresses are refused unless
+// Config.AllowPrivatePeers is set.
+//
+// Bind and Allocate are the client side, to test STUN and TURN servers.
+package turn
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pions/pkg/stun"
+)
+
+const (
+	// DefaultLifetime is the lifetime of an allocation whose client asks
+	// for none or for less.
+	DefaultLifetime = 10 * time.Minute
+	// MaxLifetime bounds the lifetime a client may ask for when
+	// Config.MaxLifetime is unset.
+	MaxLifetime = time.Hour
+
+	permissionLifetime = 5 * time.Minute
+	channelLifetime    = 10 * time.Minute
+	// nonceLifetime is how long a nonce is accepted before the client is
+	// told to retry with a new one.
+	nonceLifetime = 10 * time.Minute
+
+	minChannel = 0x4000
+	maxChannel = 0x7FFF
+	maxPacket  = 1 << 16
+
+	// handshakeTimeout bounds the TLS handshake of stream clients and
+	// writeTimeout their writes, so a stalled client cannot hold a relay.
+	handshakeTimeout = 10 * time.Second
+	writeTimeout     = 10 * time.Second
+)
+
+var (
+	errMismatch   = stun.Err437AllocationMismatch
+	errWrongCreds = stun.ErrorCode{ErrorClass: 4, ErrorNumber: 41, Reason: []byte("Wrong Credentials")}
+	errQuota      = stun.ErrorCode{ErrorClass: 4, ErrorNumber: 86, Reason: []byte("Allocation Quota Reached")}
+	errBadRequest = stun.Err400BadRequest
+	errTransport  = stun.Err442UnsupportedTransportProtocol
+	errCapacity   = stun.Err508InsufficentCapacity
+	errForbidden  = stun.ErrorCode{ErrorClass: 4, ErrorNumber: 3, Reason: []byte("Forbidden")}
+)
+
+// deniedNets are the peer addresses that are not relayed by default on
+// top of the loopback, private, link-local and multicast ones.
+var deniedNets = []*net.IPNet{
+	cidr("0.0.0.0/8"),     // this network
+	cidr("100.64.0.0/10"), // shared address space of carrier-grade NATs
+	cidr("255.255.255.255/32"),
+}
+
+func cidr(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// Config is the configuration of a Server.
+type Config struct {
+	// Realm is the realm of the long-term credentials, "ssh-p2p" if empty.
+	Realm string
+	// Users maps the user names allowed to allocate to their passwords.
+	// Without users the server only answers binding requests.
+	Users map[string]string
+	// RelayIP is the address advertised for relayed transport addresses.
+	// It defaults to the address the server listens on, which must then
+	// be specified.
+	RelayIP net.IP
+	// PortMin and PortMax bound the ports of relayed transport addresses;
+	// when zero the system picks them.
+	PortMin, PortMax int
+	// Quota is the number of allocations a user may hold at a time, 0 for
+	// unlimited.
+	Quota int
+	// DefaultLifetime and MaxLifetime bound the lifetime of allocations;
+	// see the constants of the same names.
+	DefaultLifetime, MaxLifetime time.Duration
+	// AllowPrivatePeers lets clients reach peers on loopback, private and
+	// link-local addresses and on the server's own addresses. By default
+	// those are refused, so that the relay cannot reach the networks and
+	// services behind the server; only the relays of other allocations
+	// are reachable on its own addresses.
+	AllowPrivatePeers bool
+	// Logger receives the server's logs, slog.Default() if nil.
+	Logger *slog.Logger
+}
+
+// Server answers STUN and TURN requests received on a PacketConn and,
+// see ServeListener, on stream connections.
+type Server struct {
+	conn   net.PacketConn
+	conf   Config
+	log    *slog.Logger
+	secret []byte // keys the nonces
+	host   net.IP // the address relayed transport addresses listen on
+	own    []net.IP
+
+	mu        sync.Mutex
+	allocs    map[string]*allocation // by client key
+	relays    map[int]bool           // the ports of relayed transport addresses
+	next      int                    // the port tried first by the next allocation
+	listeners []net.Listener
+	streams   map[net.Conn]bool
+	closed    bool
+}
+
+// NewServer returns a server answering on conn; see Serve.
+func NewServer(conn net.PacketConn, conf Config) (*Server, error) {
+	if conf.Realm == "" {
+		conf.Realm = "ssh-p2p"
+	}
+	if conf.DefaultLifetime <= 0 {
+		conf.DefaultLifetime = DefaultLifetime
+	}
+	if conf.MaxLifetime <= 0 {
+		conf.MaxLifetime = MaxLifetime
+	}
+	if conf.MaxLifetime < conf.DefaultLifetime {
+		return nil, fmt.Errorf("turn: max lifetime %v below default lifetime %v", conf.MaxLifetime, conf.DefaultLifetime)
+	}
+	if conf.PortMin < 0 || conf.PortMax > 65535 || conf.PortMin > conf.PortMax || (conf.PortMin == 0) != (conf.PortMax == 0) {
+		return nil, fmt.Errorf("turn: invalid port range %d-%d", conf.PortMin, conf.PortMax)
+	}
+	if conf.Logger == nil {
+		conf.Logger = slog.Default()
+	}
+	s := &Server{
+		conn:    conn,
+		conf:    conf,
+		log:     conf.Logger,
+		secret:  make([]byte, 16),
+		allocs:  map[string]*allocation{},
+		relays:  map[int]bool{},
+		streams: map[net.Conn]bool{},
+	}
+	if _, err := rand.Read(s.secret); err != nil {
+		return nil, err
+	}
+	if a, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		s.host = a.IP
+	}
+	if s.conf.RelayIP == nil && len(conf.Users) > 0 {
+		if s.host == nil || s.host.IsUnspecified() {
+			return nil, errors.New("turn: relay address needed when listening on all addresses")
+		}
+		s.conf.RelayIP = s.host
+	}
+	s.own = ownIPs(s.conf.RelayIP, s.host)
+	return s, nil
+}
+
+// ownIPs returns the addresses of the server: those given and those of the
+// local interfaces.
+func ownIPs(ips ...net.IP) []net.IP {
+	var own []net.IP
+	for _, ip := range ips {
+		if ip != nil && !ip.IsUnspecified() {
+			own = append(own, ip)
+		}
+	}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, a := range addrs {
+			if n, ok := a.(*net.IPNet); ok {
+				own = append(own, n.IP)
+			}
+		}
+	}
+	return own
+}
+
+func (s *Server) isOwn(ip net.IP) bool {
+	for _, v := range s.own {
+		if v.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedIP reports whether clients may install permissions for ip: see
+// Config.AllowPrivatePeers. The server's own addresses are allowed here,
+// and only their relay ports by allowed.
+func (s *Server) allowedIP(ip net.IP) bool {
+	if s.conf.AllowPrivatePeers || s.isOwn(ip) {
+		return true
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, n := range deniedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowed reports whether data may be relayed between an allocation and
+// peer.
+func (s *Server) allowed(peer *net.UDPAddr) bool {
+	if s.conf.AllowPrivatePeers {
+		return true
+	}
+	if s.isOwn(peer.IP) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.relays[peer.Port]
+	}
+	return s.allowedIP(peer.IP)
+}
+
+// Serve answers requests until the connection fails or the server is
+// closed, which returns nil.
+func (s *Server) Serve() error {
+	buf := make([]byte, maxPacket)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return err
+		}
+		s.packet(client{addr: addr, conn: s.conn, transport: "udp"}, append([]byte(nil), buf[:n]...))
+	}
+}
+
+// ServeListener answers requests on the connections accepted from l, TURN
+// over TCP or, when l is a TLS listener, over TLS (RFC 5766 section 2.1).
+// Peers are still relayed over UDP. A connection holds one allocation at
+// most, which is released when the connection closes. ServeListener
+// returns when l fails or nil once the server is closed.
+func (s *Server) ServeListener(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return nil
+	}
+	s.listeners = append(s.listeners, l)
+	s.mu.Unlock()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return err
+		}
+		go s.serveStream(conn)
+	}
+}
+
+// serveStream reads the STUN and ChannelData messages framed on conn.
+func (s *Server) serveStream(conn net.Conn) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		conn.Close()
+		return
+	}
+	s.streams[conn] = true
+	s.mu.Unlock()
+	c := client{addr: conn.RemoteAddr(), conn: &stream{conn: conn}, transport: "tcp"}
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.streams, conn)
+		a := s.allocs[c.key()]
+		s.mu.Unlock()
+		if a != nil {
+			s.remove(a, "allocation closed")
+		}
+	}()
+	if tc, ok := conn.(*tls.Conn); ok {
+		c.transport = "tls"
+		tc.SetDeadline(time.Now().Add(handshakeTimeout))
+		if err := tc.Handshake(); err != nil {
+			s.log.Debug("tls handshake failed", "client", c.addr, "err", err)
+			return
+		}
+		tc.SetDeadline(time.Time{})
+	}
+	r := bufio.NewReader(conn)
+	for {
+		b, err := readFrame(r)
+		if err != nil {
+			if err == errFraming {
+				s.log.Debug("stream out of sync", "client", c.addr)
+			}
+			return
+		}
+		s.packet(c, b)
+	}
+}
+
+var errFraming = errors.New("turn: neither a STUN message nor ChannelData")
+
+// readFrame reads the next STUN or ChannelData message of a stream.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	head, err := r.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	n := int(binary.BigEndian.Uint16(head[2:]))
+	switch head[0] & 0xC0 {
+	case 0x00:
+		n += 20
+	case 0x40:
+		// ChannelData is padded to 4 bytes on streams.
+		n = 4 + (n+3)&^3
+	default:
+		return nil, errFraming
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// packet handles a STUN or ChannelData message b from c.
+func (s *Server) packet(c client, b []byte) {
+	switch {
+	case len(b) >= 4 && b[0]&0xC0 == 0x40:
+		s.channelData(c, b)
+	case wellFormed(b):
+		m, err := stun.NewMessage(b)
+		if err != nil {
+			return
+		}
+		s.handle(c, m)
+	}
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Close stops the server, its listeners and connections, and releases
+// every allocation.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	allocs := s.allocs
+	s.allocs, s.relays = map[string]*allocation{}, map[int]bool{}
+	listeners, streams := s.listeners, s.streams
+	s.listeners, s.streams = nil, map[net.Conn]bool{}
+	s.mu.Unlock()
+	for _, a := range allocs {
+		a.close()
+	}
+	for _, l := range listeners {
+		l.Close()
+	}
+	for conn := range streams {
+		conn.Close()
+	}
+	return s.conn.Close()
+}
+
+// client is where a request came from: the client's address and the
+// connection that reaches it, the server's PacketConn or a stream.
+type client struct {
+	addr net.Addr
+	conn interface {
+		WriteTo(b []byte, addr net.Addr) (int, error)
+	}
+	transport string // udp, tcp or tls
+}
+
+// key identifies the client's allocation.
+func (c client) key() string {
+	return c.addr.Network() + "/" + c.addr.String()
+}
+
+// stream writes the messages of one client on its connection.
+type stream struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (st *stream) WriteTo(b []byte, _ net.Addr) (int, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return st.conn.Write(b)
+}
+
+// wellFormed reports whether b is a STUN message whose attributes fit, as
+// stun.NewMessage does not check them.
+func wellFormed(b []byte) bool {
+	if !stun.IsSTUN(b) || int(binary.BigEndian.Uint16(b[2:]))+20 != len(b) {
+		return false
+	}
+	for off := 20; off < len(b); {
+		if off+4 > len(b) {
+			return false
+		}
+		l := int(binary.BigEndian.Uint16(b[off+2:]))
+		off += 4 + (l+3)&^3
+		if off > len(b) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) handle(c client, m *stun.Message) {
+	switch {
+	case m.Class == stun.ClassRequest && m.Method == stun.MethodBinding:
+		s.reply(c, m, nil, &stun.XorMappedAddress{XorAddress: xorAddr(c.addr)})
+	case m.Class == stun.ClassIndication && m.Method == stun.MethodSend:
+		s.send(c, m)
+	case m.Class == stun.ClassRequest:
+		switch m.Method {
+		case stun.MethodAllocate:
+			s.allocate(c, m)
+		case stun.MethodRefresh, stun.MethodCreatePermission, stun.MethodChannelBind:
+			s.request(c, m)
+		default:
+			s.fail(c, m, nil, errBadRequest)
+		}
+	}
+}
+
+// reply sends a success response to m, with MESSAGE-INTEGRITY when key
+// is set.
+func (s *Server) reply(c client, m *stun.Message, key []byte, attrs ...stun.Attribute) {
+	s.respond(c, m, stun.ClassSuccessResponse, key, attrs...)
+}
+
+// fail sends an error response to m.
+func (s *Server) fail(c client, m *stun.Message, key []byte, code stun.ErrorCode, attrs ...stun.Attribute) {
+	s.respond(c, m, stun.ClassErrorResponse, key, append([]stun.Attribute{&code}, attrs...)...)
+}
+
+func (s *Server) respond(c client, m *stun.Message, class stun.MessageClass, key []byte, attrs ...stun.Attribute) {
+	if key != nil {
+		attrs = append(attrs, &stun.MessageIntegrity{Key: key})
+	}
+	attrs = append(attrs, &stun.Fingerprint{})
+	res, err := stun.Build(class, m.Method, m.TransactionID, attrs...)
+	if err != nil {
+		s.log.Error("building response failed", "method", m.Method, "err", err)
+		return
+	}
+	c.conn.WriteTo(res.Pack(), c.addr)
+}
+
+// authenticate checks the long-term credentials of m and returns its user
+// and key, or answers m with the challenge and returns a nil key.
+func (s *Server) authenticate(c client, m *stun.Message) (string, []byte) {
+	challenge := []stun.Attribute{&stun.Realm{Realm: s.conf.Realm}, &stun.Nonce{Nonce: s.nonce()}}
+	mi, ok := m.GetOneAttribute(stun.AttrMessageIntegrity)
+	if !ok {
+		s.fail(c, m, nil, stun.Err401Unauthorized, challenge...)
+		return "", nil
+	}
+	user, ok1 := m.GetOneAttribute(stun.AttrUsername)
+	realm, ok2 := m.GetOneAttribute(stun.AttrRealm)
+	nonce, ok3 := m.GetOneAttribute(stun.AttrNonce)
+	if !ok1 || !ok2 || !ok3 {
+		s.fail(c, m, nil, errBadRequest)
+		return "", nil
+	}
+	if !s.validNonce(string(nonce.Value)) {
+		s.fail(c, m, nil, stun.Err438StaleNonce, challenge...)
+		return "", nil
+	}
+	name := string(user.Value)
+	pass, ok := s.conf.Users[name]
+	if !ok || string(realm.Value) != s.conf.Realm {
+		s.log.Warn("unknown user", "user", name, "client", c.addr)
+		s.fail(c, m, nil, stun.Err401Unauthorized, challenge...)
+		return "", nil
+	}
+	key := longTermKey(name, s.conf.Realm, pass)
+	if !validIntegrity(m, mi, key) {
+		s.log.Warn("wrong credentials", "user", name, "client", c.addr)
+		s.fail(c, m, nil, stun.Err401Unauthorized, challenge...)
+		return "", nil
+	}
+	return name, key
+}
+
+// longTermKey is the MESSAGE-INTEGRITY key of long-term credentials.
+func longTermKey(user, realm, pass string) []byte {
+	sum := md5.Sum([]byte(user + ":" + realm + ":" + pass))
+	return sum[:]
+}
+
+// validIntegrity checks the MESSAGE-INTEGRITY attribute mi of m, which
+// covers the message up to mi with the length set as if mi ended it.
+func validIntegrity(m *stun.Message, mi *stun.RawAttribute, key []byte) bool {
+	if len(mi.Value) != sha1.Size || mi.Offset < 20 {
+		return false
+	}
+	b := append([]byte(nil), m.Raw[:mi.Offset]...)
+	binary.BigEndian.PutUint16(b[2:], uint16(mi.Offset-20+4+sha1.Size))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(b)
+	return hmac.Equal(mac.Sum(nil), mi.Value)
+}
+
+// nonce returns a nonce carrying its time of issue and a MAC of it.
+func (s *Server) nonce() string {
+	t := strconv.FormatInt(time.Now().Unix(), 16)
+	return t + "-" + s.nonceMAC(t)
+}
+
+func (s *Server) nonceMAC(t string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	io.WriteString(mac, t)
+	return hex.EncodeToString(mac.Sum(nil)[:12])
+}
+
+func (s *Server) validNonce(n string) bool {
+	t, sig, ok := strings.Cut(n, "-")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.nonceMAC(t))) {
+		return false
+	}
+	issued, err := strconv.ParseInt(t, 16, 64)
+	return err == nil && time.Since(time.Unix(issued, 0)) < nonceLifetime
+}
+
+// lifetime returns the lifetime requested by m, clamped to the configured
+// bounds. A zero lifetime is kept, as it deletes an allocation.
+func (s *Server) lifetime(m *stun.Message) (time.Duration, bool) {
+	attr, ok := m.GetOneAttribute(stun.AttrLifetime)
+	if !ok {
+		return s.conf.DefaultLifetime, true
+	}
+	var l stun.Lifetime
+	if err := l.Unpack(m, attr); err != nil {
+		return 0, false
+	}
+	d := time.Duration(l.Duration) * time.Second
+	switch {
+	case d == 0 && m.Method == stun.MethodRefresh:
+		return 0, true
+	case d < s.conf.DefaultLifetime:
+		return s.conf.DefaultLifetime, true
+	case d > s.conf.MaxLifetime:
+		return s.conf.MaxLifetime, true
+	}
+	return d, true
+}
+
+func (s *Server) allocate(c client, m *stun.Message) {
+	user, key := s.authenticate(c, m)
+	if key == nil {
+		return
+	}
+	s.mu.Lock()
+	a := s.allocs[c.key()]
+	s.mu.Unlock()
+	if a != nil {
+		if a.txid == string(m.TransactionID) {
+			// A retransmission of the request that made a.
+			s.reply(c, m, key, a.attrs(c.addr)...)
+			return
+		}
+		s.fail(c, m, key, errMismatch)
+		return
+	}
+	attr, ok := m.GetOneAttribute(stun.AttrRequestedTransport)
+	if !ok || len(attr.Value) == 0 {
+		s.fail(c, m, key, errBadRequest)
+		return
+	}
+	var rt stun.RequestedTransport
+	if rt.Unpack(m, attr) != nil {
+		s.fail(c, m, key, errTransport)
+		return
+	}
+	lifetime, ok := s.lifetime(m)
+	if !ok {
+		s.fail(c, m, key, errBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.allocs[c.key()] != nil {
+		s.fail(c, m, key, errMismatch)
+		return
+	}
+	if q := s.conf.Quota; q > 0 {
+		n := 0
+		for _, a := range s.allocs {
+			if a.user == user {
+				n++
+			}
+		}
+		if n >= q {
+			s.log.Warn("allocation quota reached", "user", user, "client", c.addr, "quota", q)
+			s.fail(c, m, key, errQuota)
+			return
+		}
+	}
+	relay, err := s.listenRelay()
+	if err != nil {
+		s.log.Warn("no relay port available", "user", user, "client", c.addr, "err", err)
+		s.fail(c, m, key, errCapacity)
+		return
+	}
+	a = newAllocation(s, c, user, relay, string(m.TransactionID), lifetime)
+	s.allocs[c.key()] = a
+	s.relays[a.port()] = true
+	s.log.Info("allocated", "user", user, "client", c.addr, "transport", c.transport, "relay", a.relayAddr(), "lifetime", lifetime)
+	s.reply(c, m, key, a.attrs(c.addr)...)
+}
+
+// listenRelay listens on a port of the configured range. s.mu is held.
+func (s *Server) listenRelay() (net.PacketConn, error) {
+	host := ""
+	if s.host != nil && !s.host.IsUnspecified() {
+		host = s.host.String()
+	}
+	if s.conf.PortMin == 0 {
+		return net.ListenPacket("udp", net.JoinHostPort(host, "0"))
+	}
+	n := s.conf.PortMax - s.conf.PortMin + 1
+	var err error
+	for i := 0; i < n; i++ {
+		port := s.conf.PortMin + (s.next+i)%n
+		var c net.PacketConn
+		if c, err = net.ListenPacket("udp", net.JoinHostPort(host, strconv.Itoa(port))); err == nil {
+			s.next = (s.next + i + 1) % n
+			return c, nil
+		}
+	}
+	return nil, err
+}
+
+// request handles the requests made on an existing allocation.
+func (s *Server) request(c client, m *stun.Message) {
+	user, key := s.authenticate(c, m)
+	if key == nil {
+		return
+	}
+	s.mu.Lock()
+	a := s.allocs[c.key()]
+	s.mu.Unlock()
+	if a == nil {
+		s.fail(c, m, key, errMismatch)
+		return
+	}
+	if a.user != user {
+		s.fail(c, m, key, errWrongCreds)
+		return
+	}
+	switch m.Method {
+	case stun.MethodRefresh:
+		lifetime, ok := s.lifetime(m)
+		if !ok {
+			s.fail(c, m, key, errBadRequest)
+			return
+		}
+		if lifetime == 0 {
+			s.remove(a, "allocation deleted")
+		} else {
+			a.refresh(lifetime)
+		}
+		s.reply(c, m, key, &stun.Lifetime{Duration: uint32(lifetime / time.Second)})
+	case stun.MethodCreatePermission:
+		attrs, ok := m.GetAllAttributes(stun.AttrXORPeerAddress)
+		if !ok {
+			s.fail(c, m, key, errBadRequest)
+			return
+		}
+		var peers []net.IP
+		for _, attr := range attrs {
+			var p stun.XorAddress
+			if p.Unpack(m, attr) != nil {
+				s.fail(c, m, key, errBadRequest)
+				return
+			}
+			if !s.allowedIP(p.IP) {
+				s.log.Warn("peer refused", "user", user, "client", c.addr, "peer", p.IP)
+				s.fail(c, m, key, errForbidden)
+				return
+			}
+			peers = append(peers, p.IP)
+		}
+		for _, ip := range peers {
+			a.permit(ip)
+		}
+		s.reply(c, m, key)
+	case stun.MethodChannelBind:
+		num, ok1 := m.GetOneAttribute(stun.AttrChannelNumber)
+		peer, ok2 := m.GetOneAttribute(stun.AttrXORPeerAddress)
+		if !ok1 || !ok2 || len(num.Value) != 4 {
+			s.fail(c, m, key, errBadRequest)
+			return
+		}
+		var p stun.XorAddress
+		if p.Unpack(m, peer) != nil {
+			s.fail(c, m, key, errBadRequest)
+			return
+		}
+		addr := &net.UDPAddr{IP: p.IP, Port: p.Port}
+		if !s.allowed(addr) {
+			s.log.Warn("peer refused", "user", user, "client", c.addr, "peer", addr)
+			s.fail(c, m, key, errForbidden)
+			return
+		}
+		if err := a.bind(binary.BigEndian.Uint16(num.Value), addr); err != nil {
+			s.fail(c, m, key, errBadRequest)
+			return
+		}
+		s.reply(c, m, key)
+	}
+}
+
+// send relays the data of a Send indication.
+func (s *Server) send(c client, m *stun.Message) {
+	s.mu.Lock()
+	a := s.allocs[c.key()]
+	s.mu.Unlock()
+	peer, ok1 := m.GetOneAttribute(stun.AttrXORPeerAddress)
+	data, ok2 := m.GetOneAttribute(stun.AttrData)
+	if a == nil || !ok1 || !ok2 {
+		return
+	}
+	var p stun.XorAddress
+	if p.Unpack(m, peer) != nil {
+		return
+	}
+	a.relayTo(&net.UDPAddr{IP: p.IP, Port: p.Port}, data.Value)
+}
+
+// channelData relays the data of a ChannelData message.
+func (s *Server) channelData(c client, b []byte) {
+	s.mu.Lock()
+	a := s.allocs[c.key()]
+	s.mu.Unlock()
+	n := int(binary.BigEndian.Uint16(b[2:]))
+	if a == nil || 4+n > len(b) {
+		return
+	}
+	if peer := a.channelPeer(binary.BigEndian.Uint16(b)); peer != nil {
+		a.relayTo(peer, b[4:4+n])
+	}
+}
+
+// remove releases a if it is still allocated.
+func (s *Server) remove(a *allocation, reason string) {
+	s.mu.Lock()
+	if s.allocs[a.client.key()] != a {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.allocs, a.client.key())
+	delete(s.relays, a.port())
+	s.mu.Unlock()
+	a.close()
+	s.log.Info(reason, "user", a.user, "client", a.client.addr, "transport", a.client.transport, "relay", a.relayAddr())
+}
+
+func xorAddr(addr net.Addr) stun.XorAddress {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return stun.XorAddress{IP: a.IP, Port: a.Port}
+	case *net.TCPAddr:
+		return stun.XorAddress{IP: a.IP, Port: a.Port}
+	}
+	return stun.XorAddress{}
+}
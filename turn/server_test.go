@@ -0,0 +1,313 @@
+package turn
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pions/pkg/stun"
+)
+
+const testTimeout = 5 * time.Second
+
+// testServer serves conf on loopback over UDP and TCP and returns the
+// addresses it listens on.
+func testServer(t *testing.T, conf Config) (udp, tcp string) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Users == nil {
+		conf.Users = map[string]string{"alice": "secret"}
+	}
+	s, err := NewServer(conn, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	go s.ServeListener(l)
+	t.Cleanup(func() { s.Close() })
+	return conn.LocalAddr().String(), l.Addr().String()
+}
+
+// testClient holds an allocation of alice.
+type testClient struct {
+	t     *testing.T
+	tr    *transaction
+	auth  []stun.Attribute
+	relay *net.UDPAddr
+}
+
+func newTestClient(t *testing.T, network, addr string) *testClient {
+	t.Helper()
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	tr := &transaction{conn: conn, deadline: time.Now().Add(testTimeout)}
+	if network == "tcp4" {
+		tr.r = bufio.NewReader(conn)
+	}
+	relay, auth, err := tr.allocate("alice", "secret")
+	if err != nil {
+		t.Fatalf("allocate over %s: %v", network, err)
+	}
+	return &testClient{t: t, tr: tr, auth: auth, relay: relay}
+}
+
+// request sends a request with attrs and returns the error code of the
+// response, 0 for a success.
+func (c *testClient) request(method stun.Method, attrs ...stun.Attribute) int {
+	c.t.Helper()
+	res, err := c.tr.do(method, append(attrs, c.auth...)...)
+	if err != nil {
+		c.t.Fatalf("%v: %v", method, err)
+	}
+	if res.Class == stun.ClassErrorResponse {
+		return responseError(res).Code
+	}
+	return 0
+}
+
+func (c *testClient) permit(ip net.IP) int {
+	return c.request(stun.MethodCreatePermission, peerAddr(&net.UDPAddr{IP: ip}))
+}
+
+func (c *testClient) bind(num uint16, peer *net.UDPAddr) int {
+	return c.request(stun.MethodChannelBind, channelNumber(num), peerAddr(peer))
+}
+
+func (c *testClient) send(peer *net.UDPAddr, b []byte) {
+	c.t.Helper()
+	m, err := stun.Build(stun.ClassIndication, stun.MethodSend, stun.GenerateTransactionId(), peerAddr(peer), &stun.Data{Data: b})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.write(m.Pack())
+}
+
+// sendChannel sends b as ChannelData on num, padded over streams.
+func (c *testClient) sendChannel(num uint16, b []byte) {
+	n := 4 + len(b)
+	if c.tr.r != nil {
+		n = 4 + (len(b)+3)&^3
+	}
+	msg := make([]byte, n)
+	binary.BigEndian.PutUint16(msg, num)
+	binary.BigEndian.PutUint16(msg[2:], uint16(len(b)))
+	copy(msg[4:], b)
+	c.write(msg)
+}
+
+func (c *testClient) write(b []byte) {
+	c.t.Helper()
+	if _, err := c.tr.conn.Write(b); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// recv returns the next message from the server within d, nil if none.
+func (c *testClient) recv(d time.Duration) []byte {
+	c.tr.conn.SetReadDeadline(time.Now().Add(d))
+	if c.tr.r != nil {
+		b, err := readFrame(c.tr.r)
+		if err != nil {
+			return nil
+		}
+		return b
+	}
+	buf := make([]byte, maxPacket)
+	n, err := c.tr.conn.Read(buf)
+	if err != nil {
+		return nil
+	}
+	return buf[:n]
+}
+
+// recvData returns the peer and data of the next Data indication.
+func (c *testClient) recvData() (*net.UDPAddr, []byte) {
+	c.t.Helper()
+	m := parse(c.recv(testTimeout))
+	if m == nil || m.Class != stun.ClassIndication || m.Method != stun.MethodData {
+		c.t.Fatalf("got %v, want a Data indication", m)
+	}
+	attr, ok1 := m.GetOneAttribute(stun.AttrXORPeerAddress)
+	data, ok2 := m.GetOneAttribute(stun.AttrData)
+	var p stun.XorAddress
+	if !ok1 || !ok2 || p.Unpack(m, attr) != nil {
+		c.t.Fatalf("malformed Data indication %v", m)
+	}
+	return &net.UDPAddr{IP: p.IP, Port: p.Port}, data.Value
+}
+
+func peerAddr(a *net.UDPAddr) *stun.XorPeerAddress {
+	return &stun.XorPeerAddress{XorAddress: stun.XorAddress{IP: a.IP, Port: a.Port}}
+}
+
+// channelNumber packs a CHANNEL-NUMBER with its reserved bytes, which
+// stun.ChannelNumber leaves out.
+type channelNumber uint16
+
+func (n channelNumber) Pack(m *stun.Message) error {
+	v := make([]byte, 4)
+	binary.BigEndian.PutUint16(v, uint16(n))
+	m.AddAttribute(stun.AttrChannelNumber, v)
+	return nil
+}
+
+func (channelNumber) Unpack(*stun.Message, *stun.RawAttribute) error {
+	return nil
+}
+
+// testPeer listens on loopback.
+func testPeer(t *testing.T) *net.UDPConn {
+	t.Helper()
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readPeer returns what peer receives within d and from whom.
+func readPeer(peer *net.UDPConn, d time.Duration) ([]byte, *net.UDPAddr) {
+	buf := make([]byte, maxPacket)
+	peer.SetReadDeadline(time.Now().Add(d))
+	n, addr, err := peer.ReadFromUDP(buf)
+	if err != nil {
+		return nil, nil
+	}
+	return buf[:n], addr
+}
+
+func TestBind(t *testing.T) {
+	udp, _ := testServer(t, Config{})
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	server, _ := net.ResolveUDPAddr("udp4", udp)
+	mapped := Bind(conn, []*net.UDPAddr{server}, testTimeout)
+	if mapped[0] == nil || mapped[0].String() != conn.LocalAddr().String() {
+		t.Errorf("mapped %v, want %v", mapped[0], conn.LocalAddr())
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	udp, tcp := testServer(t, Config{})
+	for _, tt := range []struct {
+		network, addr, pass string
+		code                int
+	}{
+		{"udp4", udp, "secret", 0},
+		{"tcp4", tcp, "secret", 0},
+		{"udp4", udp, "wrong", 401},
+	} {
+		conn, err := net.Dial(tt.network, tt.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		relay, err := Allocate(conn, "alice", tt.pass, testTimeout)
+		conn.Close()
+		var re *ResponseError
+		switch {
+		case tt.code == 0 && err != nil:
+			t.Errorf("%s: %v", tt.network, err)
+		case tt.code == 0 && !relay.IP.Equal(net.IPv4(127, 0, 0, 1)):
+			t.Errorf("%s: relay %v, want one on 127.0.0.1", tt.network, relay)
+		case tt.code != 0 && (!errors.As(err, &re) || re.Code != tt.code):
+			t.Errorf("%s with password %q: got %v, want %d", tt.network, tt.pass, err, tt.code)
+		}
+	}
+}
+
+func TestRelay(t *testing.T) {
+	udp, tcp := testServer(t, Config{AllowPrivatePeers: true})
+	for _, tt := range []struct{ network, addr string }{{"udp4", udp}, {"tcp4", tcp}} {
+		t.Run(tt.network, func(t *testing.T) {
+			c := newTestClient(t, tt.network, tt.addr)
+			peer := testPeer(t)
+			pa := peer.LocalAddr().(*net.UDPAddr)
+
+			if code := c.permit(pa.IP); code != 0 {
+				t.Fatalf("CreatePermission: %d", code)
+			}
+			c.send(pa, []byte("ping"))
+			if b, from := readPeer(peer, testTimeout); string(b) != "ping" || from.Port != c.relay.Port {
+				t.Fatalf("peer got %q from %v, want ping from %v", b, from, c.relay)
+			}
+			if _, err := peer.WriteToUDP([]byte("pong"), c.relay); err != nil {
+				t.Fatal(err)
+			}
+			if from, b := c.recvData(); string(b) != "pong" || from.String() != pa.String() {
+				t.Fatalf("client got %q from %v, want pong from %v", b, from, pa)
+			}
+
+			if code := c.bind(minChannel, pa); code != 0 {
+				t.Fatalf("ChannelBind: %d", code)
+			}
+			c.sendChannel(minChannel, []byte("odd"))
+			if b, _ := readPeer(peer, testTimeout); string(b) != "odd" {
+				t.Fatalf("peer got %q over the channel, want odd", b)
+			}
+			if _, err := peer.WriteToUDP([]byte("even"), c.relay); err != nil {
+				t.Fatal(err)
+			}
+			b := c.recv(testTimeout)
+			if len(b) < 4 || binary.BigEndian.Uint16(b) != minChannel ||
+				!bytes.Equal(b[4:4+binary.BigEndian.Uint16(b[2:])], []byte("even")) {
+				t.Fatalf("client got %x, want even on channel %#x", b, minChannel)
+			}
+		})
+	}
+}
+
+func TestPeersRefused(t *testing.T) {
+	udp, tcp := testServer(t, Config{})
+	c := newTestClient(t, "udp4", udp)
+	peer := testPeer(t)
+	pa := peer.LocalAddr().(*net.UDPAddr)
+
+	for _, ip := range []string{"10.1.2.3", "192.168.0.1", "169.254.1.1", "100.64.0.1", "fd12::1"} {
+		if code := c.permit(net.ParseIP(ip)); code != 403 {
+			t.Errorf("CreatePermission for %s: %d, want 403", ip, code)
+		}
+	}
+	if code := c.bind(minChannel, pa); code != 403 {
+		t.Errorf("ChannelBind to a port of the server: %d, want 403", code)
+	}
+	// The server's own address may be permitted, for the relays of other
+	// allocations, but its other ports are not reached.
+	if code := c.permit(pa.IP); code != 0 {
+		t.Fatalf("CreatePermission for the server: %d", code)
+	}
+	c.send(pa, []byte("ping"))
+	if b, _ := readPeer(peer, 200*time.Millisecond); b != nil {
+		t.Errorf("peer on the server got %q", b)
+	}
+	peer.WriteToUDP([]byte("pong"), c.relay)
+	if b := c.recv(200 * time.Millisecond); b != nil {
+		t.Errorf("client got %x from a port of the server", b)
+	}
+
+	other := newTestClient(t, "tcp4", tcp)
+	if code := other.permit(c.relay.IP); code != 0 {
+		t.Fatalf("CreatePermission: %d", code)
+	}
+	c.send(other.relay, []byte("hello"))
+	if from, b := other.recvData(); string(b) != "hello" || from.String() != c.relay.String() {
+		t.Errorf("relay got %q from %v, want hello from %v", b, from, c.relay)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/nobonobo/ssh-p2p/turn"
+)
+
+// loadUsers reads the TURN users of path, a name:password per line. Blank
+// lines and lines starting with # are skipped. The passwords stay out of
+// the command line, where other users of the host could read them.
+func loadUsers(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	users := map[string]string{}
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, pass, ok := strings.Cut(line, ":")
+		if !ok || name == "" || pass == "" {
+			return nil, fmt.Errorf("%s:%d: expected name:password", path, i+1)
+		}
+		users[name] = pass
+	}
+	return users, nil
+}
+
+// userNames returns the sorted names of users, for logs.
+func userNames(users map[string]string) string {
+	var names []string
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// parsePorts parses a min-max port range, empty for any port.
+func parsePorts(s string) (int, int, error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+	lo, hi, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected min-max: %q", s)
+	}
+	min, err := strconv.Atoi(lo)
+	if err != nil {
+		return 0, 0, fmt.Errorf("port range %q: %v", s, err)
+	}
+	max, err := strconv.Atoi(hi)
+	if err != nil {
+		return 0, 0, fmt.Errorf("port range %q: %v", s, err)
+	}
+	if min < 1 || max > 65535 || min > max {
+		return 0, 0, fmt.Errorf("invalid port range %q", s)
+	}
+	return min, max, nil
+}
+
+// turnListen are the addresses the TURN server listens on. UDP is always
+// served; TCP and TLS, for clients that cannot use UDP, when set.
+type turnListen struct {
+	udp, tcp, tls string
+	// cert and key are the PEM files of the TLS certificate.
+	cert, key string
+}
+
+// runTURN serves STUN and TURN on the addresses of l until SIGINT or
+// SIGTERM.
+func runTURN(l turnListen, conf turn.Config) {
+	if l.tls != "" && (l.cert == "" || l.key == "") {
+		fatal("invalid arguments", errors.New("-listen-tls needs -tls-cert and -tls-key"))
+	}
+	conn, err := net.ListenPacket("udp", l.udp)
+	if err != nil {
+		fatal("listen failed", err)
+	}
+	s, err := turn.NewServer(conn, conf)
+	if err != nil {
+		fatal("invalid arguments", err)
+	}
+	var listeners []net.Listener
+	if l.tcp != "" {
+		ln, err := net.Listen("tcp", l.tcp)
+		if err != nil {
+			fatal("listen failed", err)
+		}
+		listeners = append(listeners, ln)
+	}
+	if l.tls != "" {
+		cert, err := tls.LoadX509KeyPair(l.cert, l.key)
+		if err != nil {
+			fatal("loading tls certificate failed", err)
+		}
+		ln, err := tls.Listen("tcp", l.tls, &tls.Config{Certificates: []tls.Certificate{cert}})
+		if err != nil {
+			fatal("listen failed", err)
+		}
+		listeners = append(listeners, ln)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		s.Close()
+	}()
+	for _, ln := range listeners {
+		go func(ln net.Listener) {
+			if err := s.ServeListener(ln); err != nil {
+				fatal("turn server failed", err)
+			}
+		}(ln)
+	}
+	slog.Info("turn server started", "udp", conn.LocalAddr(), "tcp", l.tcp, "tls", l.tls, "realm", conf.Realm, "users", userNames(conf.Users))
+	if err := s.Serve(); err != nil {
+		fatal("turn server failed", err)
+	}
+	slog.Info("shutdown")
+}